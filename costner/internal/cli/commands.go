@@ -4,6 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
+
+	"costner/internal/core"
+	"costner/internal/monitor"
 )
 
 type CLI struct {
@@ -27,6 +31,14 @@ func (c *CLI) Run() {
 	switch command {
 	case "run":
 		c.runCommand()
+	case "plan":
+		c.planCommand()
+	case "replay":
+		c.replayCommand()
+	case "load":
+		c.loadCommand()
+	case "monitor":
+		c.monitorCommand()
 	case "validate":
 		c.validateCommand()
 	case "list-nodes":
@@ -43,6 +55,12 @@ func (c *CLI) Run() {
 func (c *CLI) runCommand() {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 	verbose := fs.Bool("verbose", false, "Enable verbose output")
+	concurrency := fs.Int("concurrency", core.DefaultMaxConcurrency, "Maximum number of nodes executed in parallel")
+	keepGoing := fs.Bool("keep-going", false, "Keep executing nodes that do not depend on a failed node")
+	timeout := fs.Duration("timeout", 0, "Deadline for the whole run, e.g. 2m (0 means no deadline)")
+	target := fs.String("target", "", "Only run this node and the nodes it depends on")
+	dataPath := fs.String("data", "", "Run the graph once per row of a CSV, JSON or NDJSON dataset")
+	recordPath := fs.String("record", "", "Write a trace of the run, including HTTP exchanges, to this file")
 	fs.Usage = func() {
 		fmt.Println("Usage: costner run [options] <project.costner>")
 		fmt.Println("Options:")
@@ -59,7 +77,141 @@ func (c *CLI) runCommand() {
 	}
 
 	projectPath := fs.Arg(0)
-	if err := c.runner.RunProject(projectPath, *verbose); err != nil {
+	opts := RunOptions{
+		Verbose:     *verbose,
+		Concurrency: *concurrency,
+		KeepGoing:   *keepGoing,
+		Timeout:     *timeout,
+		Target:      *target,
+		DataPath:    *dataPath,
+		RecordPath:  *recordPath,
+	}
+	if err := c.runner.RunProject(projectPath, opts); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *CLI) replayCommand() {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "Enable verbose output")
+	fs.Usage = func() {
+		fmt.Println("Usage: costner replay [options] <trace.json>")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if err := c.runner.ReplayTrace(fs.Arg(0), *verbose); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *CLI) loadCommand() {
+	fs := flag.NewFlagSet("load", flag.ExitOnError)
+	users := fs.Int("users", 10, "Number of virtual users running the graph in parallel")
+	duration := fs.Duration("duration", time.Minute, "How long to keep starting iterations, e.g. 2m")
+	rampUp := fs.Duration("ramp-up", 0, "Period over which the users are started, e.g. 30s")
+	concurrency := fs.Int("concurrency", core.DefaultMaxConcurrency, "Maximum number of nodes executed in parallel per user")
+	fs.Usage = func() {
+		fmt.Println("Usage: costner load [options] <project.costner>")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts := core.LoadOptions{
+		Users:    *users,
+		Duration: *duration,
+		RampUp:   *rampUp,
+	}
+	if err := c.runner.LoadTestProject(fs.Arg(0), opts, *concurrency); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *CLI) monitorCommand() {
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	every := fs.Duration("every", 0, "Run the project at this interval, e.g. 1m")
+	cron := fs.String("cron", "", "Run the project on a cron schedule, e.g. \"*/5 * * * *\"")
+	listen := fs.String("listen", "127.0.0.1:8089", "Address of the status page")
+	history := fs.Int("history", monitor.DefaultHistory, "Number of runs kept in the history")
+	timeout := fs.Duration("timeout", 0, "Deadline for each run, e.g. 30s (0 means no deadline)")
+	concurrency := fs.Int("concurrency", core.DefaultMaxConcurrency, "Maximum number of nodes executed in parallel")
+	fs.Usage = func() {
+		fmt.Println("Usage: costner monitor (--every <interval> | --cron <expr>) [options] <project.costner>")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 1 || (*every > 0) == (*cron != "") {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var schedule monitor.Schedule = monitor.Every(*every)
+	if *cron != "" {
+		var err error
+		schedule, err = monitor.ParseCron(*cron)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	opts := monitor.Options{
+		Schedule: schedule,
+		History:  *history,
+		Timeout:  *timeout,
+	}
+	if err := c.runner.MonitorProject(fs.Arg(0), opts, *listen, *concurrency); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *CLI) planCommand() {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	target := fs.String("target", "", "Only plan this node and the nodes it depends on")
+	dataPath := fs.String("data", "", "Plan with the first row of this dataset (CSV, JSON or NDJSON) instead of the project's dataset")
+	fs.Usage = func() {
+		fmt.Println("Usage: costner plan [options] <project.costner>")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if err := c.runner.PlanProject(fs.Arg(0), *target, *dataPath); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -90,6 +242,10 @@ func (c *CLI) printUsage() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  run <project.costner>     Execute a project file")
+	fmt.Println("  plan <project.costner>    Show what a run would do without executing it")
+	fmt.Println("  replay <trace.json>       Re-run a recorded trace without network access")
+	fmt.Println("  load <project.costner>    Run a project with many virtual users and report latencies")
+	fmt.Println("  monitor <project.costner> Run a project on a schedule and serve its status")
 	fmt.Println("  validate <project.costner> Validate a project file")
 	fmt.Println("  list-nodes               List available node types")
 	fmt.Println("  help                     Show this help message")
@@ -97,5 +253,16 @@ func (c *CLI) printUsage() {
 	fmt.Println("Examples:")
 	fmt.Println("  costner run my-api-test.costner")
 	fmt.Println("  costner run --verbose my-api-test.costner")
+	fmt.Println("  costner run --concurrency 8 my-api-test.costner")
+	fmt.Println("  costner run --keep-going my-api-test.costner")
+	fmt.Println("  costner run --timeout 2m my-api-test.costner")
+	fmt.Println("  costner run --target req1 my-api-test.costner")
+	fmt.Println("  costner run --data users.csv my-api-test.costner")
+	fmt.Println("  costner run --record trace.json my-api-test.costner")
+	fmt.Println("  costner replay trace.json")
+	fmt.Println("  costner plan my-api-test.costner")
+	fmt.Println("  costner load --users 50 --duration 2m --ramp-up 30s my-api-test.costner")
+	fmt.Println("  costner monitor --every 1m my-api-test.costner")
+	fmt.Println("  costner monitor --cron \"*/5 * * * *\" my-api-test.costner")
 	fmt.Println("  costner validate my-api-test.costner")
 }
\ No newline at end of file
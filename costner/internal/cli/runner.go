@@ -3,9 +3,22 @@ package cli
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"costner/internal/persistence"
+	"costner/internal/trace"
 	"costner/internal/core"
+	"costner/internal/monitor"
+	"costner/internal/nodes"
 	"costner/pkg/types"
 )
 
@@ -13,13 +26,25 @@ type Runner struct {
 	persistence *persistence.ProjectPersistence
 }
 
+type RunOptions struct {
+	Verbose     bool
+	Concurrency int
+	KeepGoing   bool
+	Timeout     time.Duration
+	Target      string
+	DataPath    string
+	RecordPath  string
+}
+
 func NewRunner() *Runner {
 	return &Runner{
 		persistence: persistence.NewProjectPersistence(),
 	}
 }
 
-func (r *Runner) RunProject(projectPath string, verbose bool) error {
+func (r *Runner) RunProject(projectPath string, opts RunOptions) error {
+	verbose := opts.Verbose
+
 	// Load project
 	project, err := r.persistence.LoadProject(projectPath)
 	if err != nil {
@@ -38,26 +63,289 @@ func (r *Runner) RunProject(projectPath string, verbose bool) error {
 		return fmt.Errorf("failed to create graph: %w", err)
 	}
 
+	// Load dataset rows for data-driven runs
+	rows, err := r.loadDataset(project, projectPath, opts.DataPath)
+	if err != nil {
+		return err
+	}
+
 	// Execute graph
 	executor := core.NewExecutor(graph)
-	ctx := context.Background()
+	if opts.Concurrency > 0 {
+		executor.SetMaxConcurrency(opts.Concurrency)
+	}
+	executor.SetKeepGoing(opts.KeepGoing)
+	executor.SetVariables(project.Variables)
+	executor.SetHTTPSettings(project.HTTP)
+	ctx, cancel := interruptContext()
+	defer cancel()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
 
 	if verbose {
 		fmt.Println("Executing graph...")
+		unsubscribe := executor.Observe(r.printProgress)
+		defer unsubscribe()
+	}
+
+	var recording *trace.Recording
+	if opts.RecordPath != "" {
+		recording = trace.NewRecording(project, opts.Target, types.TraceOptions{
+			KeepGoing:   opts.KeepGoing,
+			Timeout:     opts.Timeout,
+			Concurrency: opts.Concurrency,
+		})
+		defer r.saveTrace(recording, opts.RecordPath)
+	}
+
+	if rows != nil {
+		return r.runDataset(ctx, executor, project, rows, opts, recording)
+	}
+
+	results, err := r.execute(ctx, executor, opts, recording)
+
+	// Display results, including partial ones from a failed run
+	if len(results) > 0 {
+		r.displayResults(results, verbose)
 	}
 
-	results, err := executor.ExecuteGraph(ctx)
 	if err != nil {
 		return fmt.Errorf("execution failed: %w", err)
 	}
 
-	// Display results
-	r.displayResults(results, verbose)
+	return nil
+}
+
+// loadDataset returns the rows of the dataset at dataPath, or of the
+// project's dataset if dataPath is empty. It returns nil rows when there is
+// no dataset.
+func (r *Runner) loadDataset(project *types.Project, projectPath, dataPath string) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	var err error
+	if dataPath != "" {
+		rows, err = r.persistence.LoadDataset(types.DatasetSource{Path: dataPath}, "")
+	} else if project.Dataset != nil {
+		rows, err = r.persistence.LoadDataset(*project.Dataset, filepath.Dir(projectPath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dataset: %w", err)
+	}
+	return rows, nil
+}
+
+// rowVariables adds the columns of a dataset row to the project variables,
+// overriding variables with the same name.
+func rowVariables(variables, row map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(variables)+len(row))
+	for key, value := range variables {
+		merged[key] = value
+	}
+	for key, value := range row {
+		merged[key] = value
+	}
+	return merged
+}
+
+// interruptContext returns a context that is cancelled on Ctrl-C or
+// SIGTERM, so that a run can stop gracefully and still report its partial
+// results. A second signal terminates the process immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			fmt.Println("\nInterrupted, stopping (press Ctrl-C again to quit immediately)")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+
+	return ctx, cancel
+}
+
+// execute runs the graph, or the target and its dependencies, adding the
+// run to recording if one is given.
+func (r *Runner) execute(ctx context.Context, executor *core.Executor, opts RunOptions, recording *trace.Recording) ([]types.ExecutionResult, error) {
+	run := func(ctx context.Context) ([]types.ExecutionResult, error) {
+		if opts.Target != "" {
+			return executor.ExecuteUpTo(ctx, opts.Target)
+		}
+		return executor.ExecuteGraph(ctx)
+	}
+	if recording == nil {
+		return run(ctx)
+	}
+	return recording.Record(ctx, executor.Variables(), run)
+}
+
+func (r *Runner) saveTrace(recording *trace.Recording, path string) {
+	if err := trace.Save(recording.Trace(), path); err != nil {
+		fmt.Printf("Failed to save trace: %v\n", err)
+		return
+	}
+	fmt.Printf("Trace written to %s\n", path)
+}
+
+// runDataset executes the graph once per dataset row. Row columns are added
+// to the project variables, overriding variables with the same name.
+func (r *Runner) runDataset(ctx context.Context, executor *core.Executor, project *types.Project, rows []map[string]interface{}, opts RunOptions, recording *trace.Recording) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("dataset contains no rows")
+	}
+
+	failed := 0
+	for i, row := range rows {
+		executor.SetVariables(rowVariables(project.Variables, row))
 
+		fmt.Printf("Iteration %d/%d (%s)\n", i+1, len(rows), formatRow(row))
+		fmt.Println()
+
+		results, err := r.execute(ctx, executor, opts, recording)
+		if len(results) > 0 {
+			r.displayResults(results, opts.Verbose)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("Iteration %d failed: %v\n", i+1, err)
+		}
+		fmt.Println()
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	fmt.Printf("Dataset summary: %d/%d iterations passed\n", len(rows)-failed, len(rows))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d iterations failed", failed, len(rows))
+	}
 	return nil
 }
 
-func (r *Runner) ValidateProject(projectPath string) error {
+func formatRow(row map[string]interface{}) string {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, row[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ReplayTrace runs the project stored in a trace again with the recorded
+// options, answering every HTTP request with the recorded response instead
+// of using the network, and reports the nodes whose outcome differs from
+// the recording. Secrets are masked in traces; a replay takes them from the
+// environment when set and otherwise runs with the masked values.
+func (r *Runner) ReplayTrace(tracePath string, verbose bool) error {
+	recorded, err := trace.Load(tracePath)
+	if err != nil {
+		return err
+	}
+	project := recorded.Project
+
+	graph, err := r.persistence.ProjectToGraph(project)
+	if err != nil {
+		return fmt.Errorf("failed to create graph: %w", err)
+	}
+
+	opts := RunOptions{
+		Verbose:     verbose,
+		Concurrency: recorded.Options.Concurrency,
+		KeepGoing:   recorded.Options.KeepGoing,
+		Timeout:     recorded.Options.Timeout,
+		Target:      recorded.Target,
+	}
+
+	executor := core.NewExecutor(graph)
+	if opts.Concurrency > 0 {
+		executor.SetMaxConcurrency(opts.Concurrency)
+	}
+	executor.SetKeepGoing(opts.KeepGoing)
+	executor.SetHTTPSettings(project.HTTP)
+	if verbose {
+		unsubscribe := executor.Observe(r.printProgress)
+		defer unsubscribe()
+	}
+
+	runCtx, cancel := interruptContext()
+	defer cancel()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, opts.Timeout)
+		defer cancel()
+	}
+
+	failed := 0
+	for i, run := range recorded.Runs {
+		if runCtx.Err() != nil {
+			break
+		}
+		if len(recorded.Runs) > 1 {
+			fmt.Printf("Replaying run %d/%d\n\n", i+1, len(recorded.Runs))
+		}
+		variables := trace.ReplayVariables(run)
+		executor.SetVariables(variables)
+		ctx := core.WithTransport(runCtx, trace.NewReplayer(run.Exchanges, variables))
+
+		results, err := r.execute(ctx, executor, opts, nil)
+		if len(results) > 0 {
+			r.displayResults(results, verbose)
+		}
+		r.displayDifferences(run.Nodes, results)
+		if err != nil {
+			failed++
+			fmt.Printf("Replay failed: %v\n", err)
+		}
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d replayed runs failed", failed, len(recorded.Runs))
+	}
+	return nil
+}
+
+func (r *Runner) displayDifferences(recorded, replayed []types.ExecutionResult) {
+	statuses := make(map[string]types.ExecutionStatus, len(replayed))
+	for _, result := range replayed {
+		statuses[result.NodeID] = result.Status
+	}
+
+	differences := 0
+	for _, result := range recorded {
+		status, exists := statuses[result.NodeID]
+		if !exists {
+			status = "not run"
+		}
+		if status == result.Status {
+			continue
+		}
+		if differences == 0 {
+			fmt.Println("Differences from the recording:")
+		}
+		fmt.Printf("- %s: recorded %s, replayed %s\n", result.NodeID, result.Status, status)
+		differences++
+	}
+	if differences == 0 {
+		fmt.Println("Replay matches the recording")
+	}
+}
+
+// LoadTestProject runs a project under load and prints per-node latency
+// statistics.
+func (r *Runner) LoadTestProject(projectPath string, opts core.LoadOptions, concurrency int) error {
 	project, err := r.persistence.LoadProject(projectPath)
 	if err != nil {
 		return fmt.Errorf("failed to load project: %w", err)
@@ -68,6 +356,240 @@ func (r *Runner) ValidateProject(projectPath string) error {
 		return fmt.Errorf("failed to create graph: %w", err)
 	}
 
+	executor := core.NewExecutor(graph)
+	if concurrency > 0 {
+		executor.SetMaxConcurrency(concurrency)
+	}
+	executor.SetVariables(project.Variables)
+	executor.SetHTTPSettings(project.HTTP)
+
+	fmt.Printf("Load testing %s: %d users for %v", project.Name, opts.Users, opts.Duration)
+	if opts.RampUp > 0 {
+		fmt.Printf(" (ramp-up %v)", opts.RampUp)
+	}
+	fmt.Println()
+	fmt.Println()
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	report, err := executor.Load(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("load test failed: %w", err)
+	}
+
+	r.displayLoadReport(report)
+
+	if report.FailedIterations > 0 {
+		return fmt.Errorf("%d of %d iterations failed", report.FailedIterations, report.Iterations)
+	}
+	return nil
+}
+
+func (r *Runner) displayLoadReport(report *types.LoadReport) {
+	fmt.Println("Load Test Results:")
+	fmt.Println("==================")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tRUNS\tREQ/S\tERRORS\tP50\tP90\tP99\tMAX\tAVG WAIT")
+	for _, node := range report.Nodes {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%.1f%%\t%v\t%v\t%v\t%v\t%v\n",
+			node.NodeID, node.Executions, node.Throughput, node.ErrorRate*100,
+			roundLatency(node.P50), roundLatency(node.P90), roundLatency(node.P99), roundLatency(node.Max),
+			roundLatency(node.AvgWait))
+	}
+	w.Flush()
+
+	fmt.Printf("\nSummary: %d iterations in %v, %d failed\n",
+		report.Iterations, report.Elapsed.Round(time.Millisecond), report.FailedIterations)
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// MonitorProject runs a project on a schedule until interrupted, serving
+// its status over HTTP on listen.
+func (r *Runner) MonitorProject(projectPath string, opts monitor.Options, listen string, concurrency int) error {
+	project, err := r.persistence.LoadProject(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to load project: %w", err)
+	}
+
+	graph, err := r.persistence.ProjectToGraph(project)
+	if err != nil {
+		return fmt.Errorf("failed to create graph: %w", err)
+	}
+
+	// Every check of a monitored project runs, even after another fails,
+	// so the status page has a result for each node of each run
+	executor := core.NewExecutor(graph)
+	if concurrency > 0 {
+		executor.SetMaxConcurrency(concurrency)
+	}
+	executor.SetKeepGoing(true)
+	executor.SetVariables(project.Variables)
+	executor.SetHTTPSettings(project.HTTP)
+
+	m := monitor.New(project.Name, executor, opts)
+	m.OnRun(r.printMonitorRun)
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to start status server: %w", err)
+	}
+	server := &http.Server{Handler: m.Handler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Monitoring %s %s\n", project.Name, opts.Schedule)
+	fmt.Printf("Status page: http://%s/ (JSON at /status.json)\n\n", listener.Addr())
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	m.Run(ctx)
+
+	status := m.Status()
+	fmt.Printf("\nMonitor stopped after %d runs, %d failed\n", status.Runs, status.Failures)
+	return nil
+}
+
+func (r *Runner) printMonitorRun(record monitor.RunRecord) {
+	timestamp := record.Start.Format("15:04:05")
+	if record.Success {
+		fmt.Printf("[%s] run %d passed in %v\n", timestamp, record.Number, record.Duration.Round(time.Millisecond))
+		return
+	}
+	fmt.Printf("[%s] run %d failed in %v: %s\n", timestamp, record.Number, record.Duration.Round(time.Millisecond), record.Error)
+}
+
+// PlanProject prints the execution plan of a project without executing any
+// node.
+func (r *Runner) PlanProject(projectPath, target, dataPath string) error {
+	project, err := r.persistence.LoadProject(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to load project: %w", err)
+	}
+
+	graph, err := r.persistence.ProjectToGraph(project)
+	if err != nil {
+		return fmt.Errorf("failed to create graph: %w", err)
+	}
+
+	// A data-driven project is planned with the columns of its first row
+	rows, err := r.loadDataset(project, projectPath, dataPath)
+	if err != nil {
+		return err
+	}
+	if rows != nil && len(rows) == 0 {
+		return fmt.Errorf("dataset contains no rows")
+	}
+
+	variables := project.Variables
+	if len(rows) > 0 {
+		variables = rowVariables(project.Variables, rows[0])
+	}
+
+	executor := core.NewExecutor(graph)
+	executor.SetVariables(variables)
+	plan, err := executor.Plan(target)
+	if err != nil {
+		return fmt.Errorf("planning failed: %w", err)
+	}
+
+	if len(rows) > 0 {
+		fmt.Printf("Dataset: %d row(s), planned with the values of row 1\n\n", len(rows))
+	}
+	r.displayPlan(project.Name, plan)
+
+	if unresolved := plan.Unresolved(); unresolved > 0 {
+		return fmt.Errorf("%d required input(s) unresolved", unresolved)
+	}
+	return nil
+}
+
+func (r *Runner) displayPlan(name string, plan *types.ExecutionPlan) {
+	fmt.Printf("Execution plan for %s:\n", name)
+	fmt.Println("==================")
+
+	sideEffects := 0
+	for i, step := range plan.Steps {
+		note := ""
+		if step.SideEffects {
+			note = " [side effects, not executed]"
+			sideEffects++
+		}
+		fmt.Printf("%d. %s (%s)%s\n", i+1, step.NodeID, step.NodeType, note)
+
+		names := make([]string, 0, len(step.Inputs))
+		for name := range step.Inputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("   %s: %v\n", name, step.Inputs[name])
+		}
+		for _, wired := range step.Wired {
+			fmt.Printf("   %s ← %s.%s\n", wired.Input, wired.SourceNode, wired.SourcePort)
+		}
+		for _, input := range step.Unresolved {
+			fmt.Printf("   ! %s: required input has no value\n", input)
+		}
+		for _, variable := range step.UndefinedVariables {
+			fmt.Printf("   ! {{%s}}: variable is not defined\n", variable)
+		}
+	}
+
+	fmt.Printf("\nSummary: %d nodes, %d with side effects, %d unresolved inputs\n",
+		len(plan.Steps), sideEffects, plan.Unresolved())
+}
+
+func (r *Runner) ValidateProject(projectPath string) error {
+	project, err := r.persistence.LoadProject(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to load project: %w", err)
+	}
+
+	// Build the graph without connections so that every invalid
+	// connection can be reported, not just the first one
+	nodesOnly := *project
+	nodesOnly.Connections = nil
+	graph, err := r.persistence.ProjectToGraph(&nodesOnly)
+	if err != nil {
+		return fmt.Errorf("failed to create graph: %w", err)
+	}
+
+	invalid := 0
+	for _, conn := range project.Connections {
+		if err := graph.AddConnection(conn); err != nil {
+			if invalid == 0 {
+				fmt.Printf("Project %s has invalid connections:\n", project.Name)
+			}
+			fmt.Printf("- %s: %v\n", conn.ID, err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("graph validation failed: %d invalid connection(s)", invalid)
+	}
+
+	for _, node := range graph.GetNodesInOrder() {
+		if err := core.ValidateNodeConfig(node.GetConfig()); err != nil {
+			if invalid == 0 {
+				fmt.Printf("Project %s has invalid node configs:\n", project.Name)
+			}
+			fmt.Printf("- %s: %v\n", node.ID(), err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("graph validation failed: %d invalid node config(s)", invalid)
+	}
+
 	// Check for cycles
 	_, err = graph.GetTopologicalOrder()
 	if err != nil {
@@ -82,7 +604,7 @@ func (r *Runner) ValidateProject(projectPath string) error {
 }
 
 func (r *Runner) ListNodeTypes() {
-	nodeTypes := []string{"env", "request", "transform", "conditional", "variable"}
+	nodeTypes := nodes.NewNodeFactory().GetAvailableNodeTypes()
 
 	fmt.Println("Available node types:")
 	for _, nodeType := range nodeTypes {
@@ -90,19 +612,61 @@ func (r *Runner) ListNodeTypes() {
 	}
 }
 
+func (r *Runner) printProgress(event types.ExecutionEvent) {
+	switch event.Type {
+	case types.EventNodeStarted:
+		fmt.Printf("→ %s started\n", event.NodeID)
+	case types.EventNodeSucceeded:
+		fmt.Printf("✓ %s finished in %v\n", event.NodeID, event.Duration)
+	case types.EventNodeFailed:
+		if event.Result != nil && event.Result.Status == types.StatusCancelled {
+			fmt.Printf("⊘ %s cancelled after %v\n", event.NodeID, event.Duration)
+			return
+		}
+		fmt.Printf("✗ %s failed after %v: %s\n", event.NodeID, event.Duration, event.Error)
+	case types.EventNodeSkipped:
+		fmt.Printf("- %s skipped: %s\n", event.NodeID, event.Result.SkipReason)
+	case types.EventGraphFinished:
+		fmt.Printf("Graph finished in %v\n\n", event.Duration)
+	}
+}
+
 func (r *Runner) displayResults(results []types.ExecutionResult, verbose bool) {
 	fmt.Println("Execution Results:")
 	fmt.Println("==================")
 
 	for _, result := range results {
 		status := "✓"
-		if !result.Success {
+		if result.Status == types.StatusSkipped {
+			status = "-"
+		} else if result.Status == types.StatusTimedOut {
+			status = "⏱"
+		} else if result.Status == types.StatusCancelled {
+			status = "⊘"
+		} else if !result.Success {
 			status = "✗"
 		}
 
-		fmt.Printf("%s Node: %s (Duration: %v)\n", status, result.NodeID, result.Duration)
+		details := ""
+		if result.Cached {
+			details += ", cached"
+		}
+		if len(result.Attempts) > 1 {
+			details += fmt.Sprintf(", %d attempts", len(result.Attempts))
+		}
+		if wait, ok := result.Outputs["wait"].(time.Duration); ok && wait > 0 {
+			details += fmt.Sprintf(", waited %v on host limits", wait)
+		}
+
+		fmt.Printf("%s Node: %s (Duration: %v%s)\n", status, result.NodeID, result.Duration, details)
 
-		if !result.Success {
+		if result.Status == types.StatusSkipped {
+			fmt.Printf("  Skipped: %s\n", result.SkipReason)
+		} else if result.Status == types.StatusTimedOut {
+			fmt.Printf("  Timed out: %s\n", result.Error)
+		} else if result.Status == types.StatusCancelled {
+			fmt.Printf("  Cancelled: %s\n", result.Error)
+		} else if !result.Success {
 			fmt.Printf("  Error: %s\n", result.Error)
 		} else if verbose {
 			fmt.Printf("  Outputs:\n")
@@ -110,15 +674,62 @@ func (r *Runner) displayResults(results []types.ExecutionResult, verbose bool) {
 				fmt.Printf("    %s: %v\n", key, value)
 			}
 		}
+
+		if verbose && len(result.Attempts) > 1 {
+			fmt.Printf("  Attempts:\n")
+			for _, attempt := range result.Attempts {
+				outcome := "ok"
+				if !attempt.Success {
+					outcome = attempt.Error
+				}
+				fmt.Printf("    #%d (%v): %s", attempt.Attempt, attempt.Duration, outcome)
+				if attempt.Delay > 0 {
+					fmt.Printf(", retrying in %v", attempt.Delay)
+				}
+				fmt.Println()
+			}
+		}
 		fmt.Println()
 	}
 
 	successCount := 0
+	failedCount := 0
+	skippedCount := 0
+	timedOutCount := 0
+	cancelledCount := 0
+	var waited time.Duration
 	for _, result := range results {
+		if wait, ok := result.Outputs["wait"].(time.Duration); ok {
+			waited += wait
+		}
 		if result.Success {
 			successCount++
+		} else if result.Status == types.StatusSkipped {
+			skippedCount++
+		} else if result.Status == types.StatusTimedOut {
+			timedOutCount++
+		} else if result.Status == types.StatusCancelled {
+			cancelledCount++
+		} else {
+			failedCount++
 		}
 	}
 
-	fmt.Printf("Summary: %d/%d nodes executed successfully\n", successCount, len(results))
+	fmt.Printf("Summary: %d/%d nodes executed successfully", successCount, len(results)-skippedCount)
+	if failedCount > 0 {
+		fmt.Printf(", %d failed", failedCount)
+	}
+	if timedOutCount > 0 {
+		fmt.Printf(", %d timed out", timedOutCount)
+	}
+	if cancelledCount > 0 {
+		fmt.Printf(", %d cancelled", cancelledCount)
+	}
+	if skippedCount > 0 {
+		fmt.Printf(", %d skipped", skippedCount)
+	}
+	fmt.Println()
+	if waited > 0 {
+		fmt.Printf("Waited %v in total on host limits\n", waited)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// captureOutput returns what fn prints to standard output.
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+	fn()
+	writer.Close()
+	return <-output
+}
+
+// writeProject saves project in a temporary directory together with the
+// given extra files and returns the project path.
+func writeProject(t *testing.T, project *types.Project, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := json.Marshal(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "project.costner")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// transform returns a transform node converting input with operation.
+func transform(id, operation string, input interface{}) types.NodeData {
+	inputs := []types.NodeInput{{Name: "operation", Value: operation}}
+	if input != nil {
+		inputs = append(inputs, types.NodeInput{Name: "input", Value: input})
+	}
+	return types.NodeData{ID: id, Type: "transform", Inputs: inputs}
+}
+
+func TestPlanDataDrivenProject(t *testing.T) {
+	project := &types.Project{
+		Name:      "users",
+		Nodes:     []types.NodeData{transform("greet", "to_string", "{{user}} with {{api_key}}")},
+		Variables: map[string]interface{}{"api_key": "k-123"},
+		Dataset:   &types.DatasetSource{Path: "users.csv"},
+	}
+	path := writeProject(t, project, map[string]string{
+		"users.csv": "user\nalice\nbob\n",
+		"other.csv": "user\ncarol\n",
+	})
+
+	var err error
+	output := captureOutput(t, func() { err = NewRunner().PlanProject(path, "", "") })
+	if err != nil {
+		t.Fatalf("plan failed: %v\n%s", err, output)
+	}
+	for _, want := range []string{"Dataset: 2 row(s)", "input: alice with ********"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("plan output lacks %q:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"k-123", "not defined"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("plan output contains %q:\n%s", unwanted, output)
+		}
+	}
+
+	// --data replaces the project's dataset
+	output = captureOutput(t, func() { err = NewRunner().PlanProject(path, "", filepath.Join(filepath.Dir(path), "other.csv")) })
+	if err != nil || !strings.Contains(output, "input: carol with ********") {
+		t.Errorf("plan with --data returned %v:\n%s", err, output)
+	}
+}
+
+func TestRunKeepGoing(t *testing.T) {
+	// bad fails, so its dependent is skipped while the independent node runs
+	project := &types.Project{
+		Name: "keep going",
+		Nodes: []types.NodeData{
+			transform("bad", "to_int", "x"),
+			transform("dependent", "to_string", nil),
+			transform("independent", "to_string", "ok"),
+		},
+		Connections: []types.Connection{
+			{ID: "c1", SourceNode: "bad", SourcePort: "output", TargetNode: "dependent", TargetPort: "input"},
+		},
+	}
+	path := writeProject(t, project, nil)
+
+	var err error
+	output := captureOutput(t, func() { err = NewRunner().RunProject(path, RunOptions{KeepGoing: true}) })
+	if err == nil || !strings.Contains(err.Error(), "1 node(s) failed") {
+		t.Errorf("error = %v, want one failed node", err)
+	}
+	for _, want := range []string{
+		"✗ Node: bad",
+		"- Node: dependent",
+		"  Skipped: upstream node bad failed",
+		"✓ Node: independent",
+		"Summary: 1/2 nodes executed successfully, 1 failed, 1 skipped",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output lacks %q:\n%s", want, output)
+		}
+	}
+}
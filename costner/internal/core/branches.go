@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+
+	"costner/pkg/types"
+)
+
+type skipInfo struct {
+	reason string
+	// failedNode is the failed node that caused the skip, if any
+	failedNode string
+}
+
+// connectionActive reports whether a connection can deliver a value. It is
+// inactive when its source node was skipped or failed, or when it leaves a
+// branch port that the source node did not take. Connections from nodes that
+// have not run yet are treated as active so prepareInputs can report them.
+func (e *Executor) connectionActive(run *runState, conn types.Connection) bool {
+	run.mutex.RLock()
+	_, skipped := run.skipped[conn.SourceNode]
+	failed := run.failed[conn.SourceNode]
+	outputs, executed := run.results[conn.SourceNode]
+	run.mutex.RUnlock()
+
+	if skipped || failed {
+		return false
+	}
+	if !executed {
+		return true
+	}
+
+	source, exists := run.graph.nodes[conn.SourceNode]
+	if !exists {
+		return true
+	}
+	if brancher, ok := source.(types.BranchingNode); ok && isBranchPort(brancher, conn.SourcePort) {
+		_, taken := outputs[conn.SourcePort]
+		return taken
+	}
+	return true
+}
+
+// skipReason returns why a node must be skipped, or an empty reason when it
+// should run. A node is skipped when any of its connected input ports has no
+// active connection left. Skips caused by a failure keep naming the node that
+// originally failed so the whole chain of dependents reports the root cause.
+func (e *Executor) skipReason(run *runState, nodeID string) skipInfo {
+	active := make(map[string]bool)
+	inactive := make(map[string]types.Connection)
+	for _, conn := range run.graph.connections {
+		if conn.TargetNode != nodeID {
+			continue
+		}
+		if e.connectionActive(run, conn) {
+			active[conn.TargetPort] = true
+		} else if _, exists := inactive[conn.TargetPort]; !exists {
+			inactive[conn.TargetPort] = conn
+		}
+	}
+
+	for _, input := range run.graph.inputs[nodeID] {
+		conn, exists := inactive[input.Name]
+		if !exists || active[input.Name] {
+			continue
+		}
+
+		run.mutex.RLock()
+		sourceSkip, sourceSkipped := run.skipped[conn.SourceNode]
+		sourceFailed := run.failed[conn.SourceNode]
+		run.mutex.RUnlock()
+
+		switch {
+		case sourceFailed:
+			return skipInfo{
+				reason:     fmt.Sprintf("upstream node %s failed", conn.SourceNode),
+				failedNode: conn.SourceNode,
+			}
+		case sourceSkipped && sourceSkip.failedNode != "":
+			return sourceSkip
+		case sourceSkipped:
+			return skipInfo{reason: fmt.Sprintf("upstream node %s was skipped", conn.SourceNode)}
+		default:
+			return skipInfo{reason: fmt.Sprintf("input %s is on untaken branch %s.%s", input.Name, conn.SourceNode, conn.SourcePort)}
+		}
+	}
+	return skipInfo{}
+}
+
+func isBranchPort(node types.BranchingNode, port string) bool {
+	for _, name := range node.BranchPorts() {
+		if name == port {
+			return true
+		}
+	}
+	return false
+}
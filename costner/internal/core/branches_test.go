@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// branchNode takes only the branch port named by its "take" config.
+type branchNode struct {
+	*funcNode
+}
+
+func newBranchNode(id, take string) *branchNode {
+	node := &branchNode{funcNode: newFuncNode(id, nil)}
+	node.Outputs = []types.NodeOutput{{Name: "yes", Type: "any"}, {Name: "no", Type: "any"}}
+	node.fn = func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{take: id}, nil
+	}
+	return node
+}
+
+func (n *branchNode) BranchPorts() []string {
+	return []string{"yes", "no"}
+}
+
+func (n *branchNode) Clone() types.Node {
+	return &branchNode{funcNode: n.funcNode.Clone().(*funcNode)}
+}
+
+func TestUntakenBranchIsSkipped(t *testing.T) {
+	graph := NewGraph()
+	graph.AddNode(newBranchNode("cond", "yes"))
+	for _, id := range []string{"taken", "untaken", "after"} {
+		graph.AddNode(newFuncNode(id, nil))
+	}
+	// Nodes fed from both branches gather them in a list input
+	for _, id := range []string{"join", "merge"} {
+		node := newFuncNode(id, nil)
+		node.Inputs = []types.NodeInput{{Name: "in", Type: types.PortList}}
+		graph.AddNode(node)
+	}
+	connections := []types.Connection{
+		{SourceNode: "cond", SourcePort: "yes", TargetNode: "taken", TargetPort: "in"},
+		{SourceNode: "cond", SourcePort: "no", TargetNode: "untaken", TargetPort: "in"},
+		{SourceNode: "untaken", SourcePort: "out", TargetNode: "after", TargetPort: "in"},
+		// join is reachable from both branches
+		{SourceNode: "cond", SourcePort: "yes", TargetNode: "join", TargetPort: "in"},
+		{SourceNode: "cond", SourcePort: "no", TargetNode: "join", TargetPort: "in"},
+		// merge follows a node on each branch
+		{SourceNode: "taken", SourcePort: "out", TargetNode: "merge", TargetPort: "in"},
+		{SourceNode: "untaken", SourcePort: "out", TargetNode: "merge", TargetPort: "in"},
+	}
+	for i, conn := range connections {
+		conn.ID = string(rune('a' + i))
+		if err := graph.AddConnection(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := NewExecutor(graph).ExecuteGraph(context.Background())
+	if err != nil {
+		t.Fatalf("skipping branches failed the run: %v", err)
+	}
+
+	byID := make(map[string]types.ExecutionResult)
+	for _, result := range results {
+		byID[result.NodeID] = result
+	}
+	tests := []struct {
+		nodeID string
+		status types.ExecutionStatus
+		reason string
+	}{
+		{"cond", types.StatusSuccess, ""},
+		{"taken", types.StatusSuccess, ""},
+		{"untaken", types.StatusSkipped, "untaken branch cond.no"},
+		{"after", types.StatusSkipped, "upstream node untaken was skipped"},
+		{"join", types.StatusSuccess, ""},
+		{"merge", types.StatusSuccess, ""},
+	}
+	for _, test := range tests {
+		result, exists := byID[test.nodeID]
+		if !exists {
+			t.Errorf("%s: no result", test.nodeID)
+			continue
+		}
+		if result.Status != test.status {
+			t.Errorf("%s: status %s, want %s", test.nodeID, result.Status, test.status)
+		}
+		if !strings.Contains(result.SkipReason, test.reason) {
+			t.Errorf("%s: skip reason %q, want it to mention %q", test.nodeID, result.SkipReason, test.reason)
+		}
+	}
+	for nodeID, want := range map[string]string{"join": "cond", "merge": "taken"} {
+		if in, _ := byID[nodeID].Inputs["in"].([]interface{}); len(in) != 1 || in[0] != want {
+			t.Errorf("%s received %v, want only the value from %s", nodeID, byID[nodeID].Inputs["in"], want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"costner/pkg/types"
+)
+
+type cacheEntry struct {
+	hash     string
+	revision uint64
+	outputs  map[string]interface{}
+}
+
+// SetCaching enables or disables reuse of node results between runs. When
+// enabled, a node that has not changed since and whose resolved inputs and
+// config hash to the same value as its last successful execution is not executed again.
+// Caching is off by default, because reusing results also skips the side
+// effects of nodes such as requests.
+func (e *Executor) SetCaching(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.caching = enabled
+}
+
+func (e *Executor) cachedOutputs(nodeID string, revision uint64, hash string) (map[string]interface{}, bool) {
+	if hash == "" {
+		return nil, false
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if !e.caching {
+		return nil, false
+	}
+	entry, exists := e.cache[nodeID]
+	if !exists || entry.hash != hash || entry.revision != revision {
+		return nil, false
+	}
+	return entry.outputs, true
+}
+
+// storeCache remembers the outputs of a node computed at the given graph
+// revision of the node. Nothing is stored while caching is disabled.
+func (e *Executor) storeCache(nodeID string, revision uint64, hash string, outputs map[string]interface{}) {
+	if hash == "" {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.caching {
+		return
+	}
+	e.cache[nodeID] = cacheEntry{hash: hash, revision: revision, outputs: outputs}
+}
+
+// hashNodeState fingerprints everything that determines a node's outputs,
+// including the version of nodes implementing types.Versioned. Nodes whose
+// inputs cannot be encoded get an empty hash and are never cached.
+func hashNodeState(node types.Node, inputs map[string]interface{}) string {
+	version := 0
+	if versioned, ok := node.(types.Versioned); ok {
+		version = versioned.Version()
+	}
+	data, err := json.Marshal(struct {
+		Type    string                 `json:"type"`
+		Version int                    `json:"version,omitempty"`
+		Inputs  map[string]interface{} `json:"inputs"`
+		Config  map[string]interface{} `json:"config"`
+	}{
+		Type:    node.Type(),
+		Version: version,
+		Inputs:  inputs,
+		Config:  node.GetConfig(),
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCachingIsOptIn(t *testing.T) {
+	calls := 0
+	counting := func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		calls++
+		return map[string]interface{}{"out": calls}, nil
+	}
+	executor := NewExecutor(newTestGraph([]*funcNode{newFuncNode("a", counting)}))
+
+	tests := []struct {
+		name       string
+		caching    bool
+		wantCalls  int
+		wantCached bool
+	}{
+		// Runs without caching always execute and leave nothing behind
+		{name: "default", wantCalls: 1},
+		{name: "default again", wantCalls: 2},
+		// The first cached run executes, later ones reuse its outputs
+		{name: "enabled", caching: true, wantCalls: 3},
+		{name: "enabled again", caching: true, wantCalls: 3, wantCached: true},
+		{name: "disabled", wantCalls: 4},
+	}
+
+	for i, test := range tests {
+		if i > 0 {
+			executor.SetCaching(test.caching)
+		}
+		results, err := executor.ExecuteGraph(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if calls != test.wantCalls {
+			t.Errorf("%s: node executed %d times, want %d", test.name, calls, test.wantCalls)
+		}
+		if results[0].Cached != test.wantCached {
+			t.Errorf("%s: cached = %v, want %v", test.name, results[0].Cached, test.wantCached)
+		}
+	}
+}
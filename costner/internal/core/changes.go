@@ -0,0 +1,62 @@
+package core
+
+import "costner/pkg/types"
+
+type graphObserverEntry struct {
+	id       int
+	observer types.GraphObserver
+}
+
+// Subscribe registers an observer for all subsequent changes to the graph
+// and returns a function that removes it.
+func (g *Graph) Subscribe(observer types.GraphObserver) func() {
+	g.observerMutex.Lock()
+	defer g.observerMutex.Unlock()
+
+	id := g.nextObserverID
+	g.nextObserverID++
+	g.observers = append(g.observers, graphObserverEntry{id: id, observer: observer})
+
+	return func() {
+		g.observerMutex.Lock()
+		defer g.observerMutex.Unlock()
+		for i, entry := range g.observers {
+			if entry.id == id {
+				g.observers = append(g.observers[:i], g.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// queue records a change to be delivered by flush. It must be called with
+// the graph locked.
+func (g *Graph) queue(change types.GraphChange) {
+	g.pending = append(g.pending, change)
+}
+
+// flush delivers queued changes to the observers. Mutating methods defer it
+// before locking the graph, so that it runs once the graph is unlocked.
+func (g *Graph) flush() {
+	g.notifyMutex.Lock()
+	defer g.notifyMutex.Unlock()
+
+	g.mutex.Lock()
+	changes := g.pending
+	g.pending = nil
+	g.mutex.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+
+	g.observerMutex.Lock()
+	observers := make([]graphObserverEntry, len(g.observers))
+	copy(observers, g.observers)
+	g.observerMutex.Unlock()
+
+	for _, change := range changes {
+		for _, entry := range observers {
+			entry.observer(change)
+		}
+	}
+}
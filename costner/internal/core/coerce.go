@@ -0,0 +1,221 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// Coerce converts a value to the representation nodes expect for a port
+// type. Values loaded from project JSON arrive as float64, string or generic
+// maps and lists; after coercion an int port always holds an int, a duration
+// port a time.Duration, a map port a map[string]interface{} and a list port
+// a []interface{}. Nil values and ports typed any are left unchanged.
+func Coerce(value interface{}, portType types.PortType) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch portType {
+	case types.PortString:
+		return coerceString(value)
+	case types.PortInt:
+		return coerceInt(value)
+	case types.PortFloat:
+		return coerceFloat(value)
+	case types.PortBool:
+		return coerceBool(value)
+	case types.PortDuration:
+		return coerceDuration(value)
+	case types.PortMap:
+		return coerceMap(value)
+	case types.PortList:
+		return coerceList(value)
+	case types.PortPrimitive:
+		switch value.(type) {
+		case string, bool, int, int64, float64, time.Duration:
+			return value, nil
+		}
+		return nil, typeError(value, portType)
+	case types.PortJSON:
+		return normalizeJSON(value, portType)
+	default:
+		return value, nil
+	}
+}
+
+func typeError(value interface{}, portType types.PortType) error {
+	if s, ok := value.(string); ok {
+		return fmt.Errorf("cannot convert %q to %s", s, portType)
+	}
+	return fmt.Errorf("cannot convert %T to %s", value, portType)
+}
+
+func coerceString(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Duration:
+		return v.String(), nil
+	case map[string]interface{}, []interface{}:
+		// Structured values become their JSON encoding, e.g. request bodies
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as string: %w", value, err)
+		}
+		return string(data), nil
+	default:
+		return nil, typeError(value, types.PortString)
+	}
+}
+
+func coerceInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("%v is not a whole number", v)
+		}
+		return int(v), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, typeError(value, types.PortInt)
+		}
+		return i, nil
+	default:
+		return nil, typeError(value, types.PortInt)
+	}
+}
+
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, typeError(value, types.PortFloat)
+		}
+		return f, nil
+	default:
+		return nil, typeError(value, types.PortFloat)
+	}
+}
+
+func coerceBool(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, typeError(value, types.PortBool)
+		}
+		return b, nil
+	default:
+		return nil, typeError(value, types.PortBool)
+	}
+}
+
+func coerceDuration(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case time.Duration, string, float64, int, int64:
+		d, err := parseDuration(value)
+		if err != nil {
+			return nil, typeError(value, types.PortDuration)
+		}
+		return d, nil
+	default:
+		return nil, typeError(value, types.PortDuration)
+	}
+}
+
+func coerceMap(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		// Accept JSON objects, e.g. headers typed into a text field
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			return nil, typeError(value, types.PortMap)
+		}
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, typeError(value, types.PortMap)
+	}
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, nil
+}
+
+func coerceList(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case string:
+		// Accept JSON arrays, e.g. a response body
+		var list []interface{}
+		if err := json.Unmarshal([]byte(v), &list); err != nil {
+			return nil, typeError(value, types.PortList)
+		}
+		return list, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, typeError(value, types.PortList)
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, nil
+}
+
+// normalizeJSON converts a value to the generic form produced by decoding
+// JSON, so that typed maps and slices look the same as loaded ones.
+func normalizeJSON(value interface{}, portType types.PortType) (interface{}, error) {
+	switch value.(type) {
+	case string, bool, float64, map[string]interface{}, []interface{}:
+		return value, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, typeError(value, portType)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, typeError(value, portType)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"costner/pkg/types"
+)
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		portType types.PortType
+		want     interface{}
+		wantErr  string
+	}{
+		{value: nil, portType: types.PortInt, want: nil},
+		{value: "x", portType: types.PortAny, want: "x"},
+
+		{value: 30.0, portType: types.PortString, want: "30"},
+		{value: 1.5, portType: types.PortString, want: "1.5"},
+		{value: true, portType: types.PortString, want: "true"},
+		{value: 2 * time.Second, portType: types.PortString, want: "2s"},
+		{value: map[string]interface{}{"a": 1.0}, portType: types.PortString, want: `{"a":1}`},
+		{value: []interface{}{1.0, "b"}, portType: types.PortString, want: `[1,"b"]`},
+
+		{value: 30.0, portType: types.PortInt, want: 30},
+		{value: " 42 ", portType: types.PortInt, want: 42},
+		{value: int64(7), portType: types.PortInt, want: 7},
+		{value: 1.5, portType: types.PortInt, wantErr: "1.5 is not a whole number"},
+		{value: "soon", portType: types.PortInt, wantErr: `cannot convert "soon" to int`},
+		{value: true, portType: types.PortInt, wantErr: "cannot convert bool to int"},
+
+		{value: 3, portType: types.PortFloat, want: 3.0},
+		{value: "2.5", portType: types.PortFloat, want: 2.5},
+
+		{value: "true", portType: types.PortBool, want: true},
+		{value: "0", portType: types.PortBool, want: false},
+		{value: "yes", portType: types.PortBool, wantErr: `cannot convert "yes" to bool`},
+
+		{value: "1m30s", portType: types.PortDuration, want: 90 * time.Second},
+		{value: 2.0, portType: types.PortDuration, want: 2 * time.Second},
+		{value: "0.5", portType: types.PortDuration, want: 500 * time.Millisecond},
+		{value: "later", portType: types.PortDuration, wantErr: `cannot convert "later" to duration`},
+
+		{value: `{"a": 1}`, portType: types.PortMap, want: map[string]interface{}{"a": 1.0}},
+		{value: map[string]string{"a": "b"}, portType: types.PortMap, want: map[string]interface{}{"a": "b"}},
+		{value: "[1]", portType: types.PortMap, wantErr: "to map"},
+
+		{value: "[1, 2]", portType: types.PortList, want: []interface{}{1.0, 2.0}},
+		{value: []string{"a", "b"}, portType: types.PortList, want: []interface{}{"a", "b"}},
+		{value: 5.0, portType: types.PortList, wantErr: "cannot convert float64 to list"},
+
+		{value: 5.0, portType: types.PortPrimitive, want: 5.0},
+		{value: []interface{}{}, portType: types.PortPrimitive, wantErr: "to primitive"},
+
+		{value: map[string]int{"a": 1}, portType: types.PortJSON, want: map[string]interface{}{"a": 1.0}},
+	}
+
+	for _, test := range tests {
+		got, err := Coerce(test.value, test.portType)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Coerce(%#v, %s) = %#v, %v, want an error containing %q", test.value, test.portType, got, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Coerce(%#v, %s) failed: %v", test.value, test.portType, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Coerce(%#v, %s) = %#v, want %#v", test.value, test.portType, got, test.want)
+		}
+	}
+}
+
+func TestCoerceNodeInputs(t *testing.T) {
+	var got interface{}
+	node := newFuncNode("n", func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		got = inputs["in"]
+		return nil, nil
+	})
+	node.Inputs = []types.NodeInput{{Name: "in", Type: "int"}}
+
+	node.SetInputValue("in", 30.0)
+	if _, err := NewExecutor(newTestGraph([]*funcNode{node})).ExecuteGraph(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != 30 {
+		t.Errorf("node received %#v, want int 30", got)
+	}
+
+	node.SetInputValue("in", "soon")
+	results, err := NewExecutor(newTestGraph([]*funcNode{node})).ExecuteGraph(context.Background())
+	want := `node n input in: cannot convert "soon" to int`
+	if err == nil || len(results) != 1 || results[0].Error != want {
+		t.Errorf("results = %+v, want the error %q", results, want)
+	}
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// Command is a reversible edit of a graph. Commands are applied through a
+// History so that they can be undone and redone.
+type Command interface {
+	Do(graph *Graph) error
+	Undo(graph *Graph) error
+	Description() string
+}
+
+// mergeable is implemented by commands that can absorb the command recorded
+// right after them, so that typing into a field is undone in one step.
+type mergeable interface {
+	merge(next Command) bool
+}
+
+// inputMergeWindow is how close together edits of the same input must be to
+// be merged into one undo step.
+const inputMergeWindow = time.Second
+
+type addNodeCommand struct {
+	node types.Node
+}
+
+func AddNodeCommand(node types.Node) Command {
+	return &addNodeCommand{node: node}
+}
+
+func (c *addNodeCommand) Do(graph *Graph) error {
+	if _, exists := graph.GetNode(c.node.ID()); exists {
+		return fmt.Errorf("node already exists: %s", c.node.ID())
+	}
+	graph.AddNode(c.node)
+	return nil
+}
+
+func (c *addNodeCommand) Undo(graph *Graph) error {
+	return graph.RemoveNode(c.node.ID())
+}
+
+func (c *addNodeCommand) Description() string {
+	return fmt.Sprintf("add node %s", c.node.ID())
+}
+
+type removedConnection struct {
+	index int
+	conn  types.Connection
+}
+
+type removeNodeCommand struct {
+	nodeID      string
+	node        types.Node
+	index       int
+	connections []removedConnection
+}
+
+func RemoveNodeCommand(nodeID string) Command {
+	return &removeNodeCommand{nodeID: nodeID}
+}
+
+func (c *removeNodeCommand) Do(graph *Graph) error {
+	node, exists := graph.GetNode(c.nodeID)
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+
+	// Remember the node, its connections and where they were
+	c.node = node
+	for i, other := range graph.GetNodesInOrder() {
+		if other.ID() == c.nodeID {
+			c.index = i
+			break
+		}
+	}
+	c.connections = nil
+	for i, conn := range graph.GetConnections() {
+		if conn.SourceNode == c.nodeID || conn.TargetNode == c.nodeID {
+			c.connections = append(c.connections, removedConnection{index: i, conn: conn})
+		}
+	}
+
+	return graph.RemoveNode(c.nodeID)
+}
+
+func (c *removeNodeCommand) Undo(graph *Graph) error {
+	graph.insertNode(c.index, c.node)
+	for _, removed := range c.connections {
+		graph.insertConnection(removed.index, removed.conn)
+	}
+	return nil
+}
+
+func (c *removeNodeCommand) Description() string {
+	return fmt.Sprintf("remove node %s", c.nodeID)
+}
+
+type addConnectionCommand struct {
+	conn types.Connection
+}
+
+func AddConnectionCommand(conn types.Connection) Command {
+	return &addConnectionCommand{conn: conn}
+}
+
+func (c *addConnectionCommand) Do(graph *Graph) error {
+	return graph.AddConnection(c.conn)
+}
+
+func (c *addConnectionCommand) Undo(graph *Graph) error {
+	return graph.RemoveConnection(c.conn.ID)
+}
+
+func (c *addConnectionCommand) Description() string {
+	return fmt.Sprintf("connect %s.%s to %s.%s", c.conn.SourceNode, c.conn.SourcePort, c.conn.TargetNode, c.conn.TargetPort)
+}
+
+type removeConnectionCommand struct {
+	connectionID string
+	removed      removedConnection
+}
+
+func RemoveConnectionCommand(connectionID string) Command {
+	return &removeConnectionCommand{connectionID: connectionID}
+}
+
+func (c *removeConnectionCommand) Do(graph *Graph) error {
+	for i, conn := range graph.GetConnections() {
+		if conn.ID == c.connectionID {
+			c.removed = removedConnection{index: i, conn: conn}
+			return graph.RemoveConnection(c.connectionID)
+		}
+	}
+	return fmt.Errorf("connection not found: %s", c.connectionID)
+}
+
+func (c *removeConnectionCommand) Undo(graph *Graph) error {
+	graph.insertConnection(c.removed.index, c.removed.conn)
+	return nil
+}
+
+func (c *removeConnectionCommand) Description() string {
+	return fmt.Sprintf("remove connection %s", c.connectionID)
+}
+
+type setInputValueCommand struct {
+	nodeID   string
+	name     string
+	value    interface{}
+	previous interface{}
+	at       time.Time
+}
+
+func SetInputValueCommand(nodeID, name string, value interface{}) Command {
+	return &setInputValueCommand{nodeID: nodeID, name: name, value: value}
+}
+
+func (c *setInputValueCommand) Do(graph *Graph) error {
+	node, exists := graph.GetNode(c.nodeID)
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+
+	found := false
+	for _, input := range node.GetInputs() {
+		if input.Name == c.name {
+			c.previous = input.Value
+			found = true
+			break
+		}
+	}
+	if !found {
+		return types.ErrInputNotFound
+	}
+
+	if c.at.IsZero() {
+		c.at = time.Now()
+	}
+	return graph.SetInputValue(c.nodeID, c.name, c.value)
+}
+
+func (c *setInputValueCommand) Undo(graph *Graph) error {
+	return graph.SetInputValue(c.nodeID, c.name, c.previous)
+}
+
+func (c *setInputValueCommand) Description() string {
+	return fmt.Sprintf("set %s.%s", c.nodeID, c.name)
+}
+
+func (c *setInputValueCommand) merge(next Command) bool {
+	other, ok := next.(*setInputValueCommand)
+	if !ok || other.nodeID != c.nodeID || other.name != c.name || other.at.Sub(c.at) > inputMergeWindow {
+		return false
+	}
+	c.value = other.value
+	c.at = other.at
+	return true
+}
+
+type moveNodeCommand struct {
+	nodeID   string
+	position types.Position
+	previous types.Position
+}
+
+func MoveNodeCommand(nodeID string, position types.Position) Command {
+	return &moveNodeCommand{nodeID: nodeID, position: position}
+}
+
+func (c *moveNodeCommand) Do(graph *Graph) error {
+	node, exists := graph.GetNode(c.nodeID)
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+	c.previous = node.GetPosition()
+	return graph.MoveNode(c.nodeID, c.position)
+}
+
+func (c *moveNodeCommand) Undo(graph *Graph) error {
+	return graph.MoveNode(c.nodeID, c.previous)
+}
+
+func (c *moveNodeCommand) Description() string {
+	return fmt.Sprintf("move node %s", c.nodeID)
+}
+
+// transaction groups commands that are undone and redone together.
+type transaction struct {
+	description string
+	commands    []Command
+}
+
+func (t *transaction) Do(graph *Graph) error {
+	for i, cmd := range t.commands {
+		if err := cmd.Do(graph); err != nil {
+			// Leave the graph as it was before the transaction
+			for j := i - 1; j >= 0; j-- {
+				t.commands[j].Undo(graph)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transaction) Undo(graph *Graph) error {
+	for i := len(t.commands) - 1; i >= 0; i-- {
+		if err := t.commands[i].Undo(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transaction) Description() string {
+	return t.description
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ValidateNodeConfig checks the execution settings of a node config: its
+// timeout, retry policy and order.
+func ValidateNodeConfig(config map[string]interface{}) error {
+	if _, err := NodeTimeout(config); err != nil {
+		return err
+	}
+	if _, err := RetryPolicyFromConfig(config); err != nil {
+		return fmt.Errorf("invalid retry policy: %w", err)
+	}
+	if _, err := NodeOrder(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseDuration reads a duration from node config. Strings use Go duration
+// syntax ("500ms", "2m"); plain numbers are taken as seconds, matching the
+// RequestNode timeout input.
+func parseDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		return time.ParseDuration(v)
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	default:
+		return 0, fmt.Errorf("cannot use %T as duration", value)
+	}
+}
+
+func parseInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%v is not a whole number", v)
+		}
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("cannot use %T as integer", value)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// NodeTimeout reads the "timeout" key of a node config. A zero duration
+// means the node is only bounded by the graph deadline.
+func NodeTimeout(config map[string]interface{}) (time.Duration, error) {
+	timeout, err := parseDuration(config["timeout"])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid timeout: %v is negative", timeout)
+	}
+	return timeout, nil
+}
+
+// invoke calls node.Execute but returns as soon as ctx is done, so a node
+// that ignores its context cannot hold up the run past its deadline.
+func invoke(ctx context.Context, node types.Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	type outcome struct {
+		outputs map[string]interface{}
+		err     error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		outputs, err := node.Execute(ctx, inputs)
+		done <- outcome{outputs: outputs, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.outputs, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// isCancelled reports whether a node failed because the run was cancelled
+// rather than because of a deadline.
+func isCancelled(graphCtx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) && errors.Is(graphCtx.Err(), context.Canceled)
+}
+
+// timeoutError explains whether a timed out node hit its own timeout or the
+// deadline of the whole graph.
+func timeoutError(graphCtx context.Context, timeout time.Duration, err error) error {
+	if errors.Is(graphCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("graph deadline exceeded: %w", err)
+	}
+	if timeout > 0 {
+		return fmt.Errorf("node timed out after %v: %w", timeout, err)
+	}
+	return fmt.Errorf("timed out: %w", err)
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// sleeping returns a node function that takes d, ignoring its context.
+func sleeping(d time.Duration) func(context.Context, map[string]interface{}) (map[string]interface{}, error) {
+	return func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		time.Sleep(d)
+		return map[string]interface{}{"out": "late"}, nil
+	}
+}
+
+func TestNodeTimeout(t *testing.T) {
+	slow := newFuncNode("slow", sleeping(time.Second))
+	slow.Config["timeout"] = "20ms"
+	executor := NewExecutor(newTestGraph([]*funcNode{slow}))
+
+	start := time.Now()
+	results, err := executor.ExecuteGraph(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("run took %v, the node timeout was not enforced", elapsed)
+	}
+	if err == nil {
+		t.Fatal("run with a timed out node succeeded")
+	}
+
+	result := results[0]
+	if result.Status != types.StatusTimedOut {
+		t.Errorf("status %s, want %s", result.Status, types.StatusTimedOut)
+	}
+	if !strings.Contains(result.Error, "node timed out after 20ms") {
+		t.Errorf("error %q does not name the node timeout", result.Error)
+	}
+}
+
+func TestGraphDeadline(t *testing.T) {
+	// slow -> after, with other queued behind slow
+	nodes := []*funcNode{newFuncNode("slow", sleeping(time.Second)), newFuncNode("after", nil), newFuncNode("other", nil)}
+	executor := NewExecutor(newTestGraph(nodes, [2]string{"slow", "after"}))
+	executor.SetMaxConcurrency(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, err := executor.ExecuteGraph(ctx)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("run took %v, the deadline was not enforced", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("run returned %v, want a deadline error", err)
+	}
+
+	want := "slow:timed_out after:skipped(graph deadline exceeded) other:skipped(graph deadline exceeded)"
+	if got := statuses(results); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if !strings.Contains(results[0].Error, "graph deadline exceeded") {
+		t.Errorf("slow node error %q does not name the graph deadline", results[0].Error)
+	}
+}
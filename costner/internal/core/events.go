@@ -0,0 +1,112 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"costner/pkg/types"
+)
+
+type observerEntry struct {
+	id       int
+	observer types.ExecutionObserver
+}
+
+type eventHub struct {
+	observers []observerEntry
+	nextID    int
+	mutex     sync.Mutex
+	emitMutex sync.Mutex
+}
+
+// Observe registers an observer for all subsequent execution events and
+// returns a function that removes it. Observers run synchronously on the
+// executing goroutine, so slow observers slow down execution.
+func (e *Executor) Observe(observer types.ExecutionObserver) func() {
+	e.events.mutex.Lock()
+	defer e.events.mutex.Unlock()
+
+	id := e.events.nextID
+	e.events.nextID++
+	e.events.observers = append(e.events.observers, observerEntry{id: id, observer: observer})
+
+	return func() {
+		e.events.mutex.Lock()
+		defer e.events.mutex.Unlock()
+		for i, entry := range e.events.observers {
+			if entry.id == id {
+				e.events.observers = append(e.events.observers[:i], e.events.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Events returns a channel receiving all subsequent execution events and a
+// cancel function that unsubscribes and closes the channel. Execution blocks
+// while the channel buffer is full, until the event is read or cancel is called.
+func (e *Executor) Events(buffer int) (<-chan types.ExecutionEvent, func()) {
+	events := make(chan types.ExecutionEvent, buffer)
+	done := make(chan struct{})
+
+	unsubscribe := e.Observe(func(event types.ExecutionEvent) {
+		select {
+		case events <- event:
+		case <-done:
+		}
+	})
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+
+			// Wait for any in-flight delivery before closing the channel
+			e.events.emitMutex.Lock()
+			close(events)
+			e.events.emitMutex.Unlock()
+		})
+	}
+
+	return events, cancel
+}
+
+func (e *Executor) emit(event types.ExecutionEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	e.events.emitMutex.Lock()
+	defer e.events.emitMutex.Unlock()
+
+	e.events.mutex.Lock()
+	observers := make([]observerEntry, len(e.events.observers))
+	copy(observers, e.events.observers)
+	e.events.mutex.Unlock()
+
+	for _, entry := range observers {
+		entry.observer(event)
+	}
+}
+
+func (e *Executor) emitResult(result types.ExecutionResult) {
+	event := types.ExecutionEvent{
+		NodeID:   result.NodeID,
+		Duration: result.Duration,
+		Outputs:  result.Outputs,
+		Error:    result.Error,
+		Result:   &result,
+	}
+
+	switch result.Status {
+	case types.StatusSuccess:
+		event.Type = types.EventNodeSucceeded
+	case types.StatusSkipped:
+		event.Type = types.EventNodeSkipped
+	default:
+		event.Type = types.EventNodeFailed
+	}
+
+	e.emit(event)
+}
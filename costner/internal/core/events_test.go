@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// describe formats an event as "type node", adding the payload that
+// matters for its type.
+func describe(event types.ExecutionEvent) string {
+	switch event.Type {
+	case types.EventNodeSucceeded:
+		return fmt.Sprintf("%s %s %v", event.Type, event.NodeID, event.Outputs)
+	case types.EventNodeFailed:
+		return fmt.Sprintf("%s %s %s", event.Type, event.NodeID, event.Error)
+	case types.EventNodeSkipped:
+		return fmt.Sprintf("%s %s %s", event.Type, event.NodeID, event.Result.SkipReason)
+	case types.EventGraphFinished:
+		return fmt.Sprintf("%s %d results: %s", event.Type, len(event.Results), event.Error)
+	default:
+		return fmt.Sprintf("%s %s", event.Type, event.NodeID)
+	}
+}
+
+func TestExecutionEvents(t *testing.T) {
+	// a -> b -> c, where b fails
+	nodes := []*funcNode{newFuncNode("a", nil), newFuncNode("b", failing), newFuncNode("c", nil)}
+	executor := NewExecutor(newTestGraph(nodes, [2]string{"a", "b"}, [2]string{"b", "c"}))
+	executor.SetMaxConcurrency(1)
+
+	var events []types.ExecutionEvent
+	unsubscribe := executor.Observe(func(event types.ExecutionEvent) {
+		events = append(events, event)
+	})
+	executor.ExecuteGraph(context.Background())
+	unsubscribe()
+
+	want := []string{
+		"node_started a",
+		"node_succeeded a map[out:a]",
+		"node_started b",
+		"node_failed b boom",
+		"node_skipped c run stopped after b failed",
+		"graph_finished 3 results: execution stopped at node b: boom",
+	}
+	got := make([]string, len(events))
+	for i, event := range events {
+		got[i] = describe(event)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events:\n%q\nwant:\n%q", got, want)
+	}
+
+	for _, event := range events {
+		if event.Timestamp.IsZero() {
+			t.Errorf("%s event has no timestamp", event.Type)
+		}
+		if event.Type != types.EventNodeStarted && event.Type != types.EventGraphFinished {
+			if event.Result == nil || event.Result.NodeID != event.NodeID {
+				t.Errorf("%s %s event carries result %+v", event.Type, event.NodeID, event.Result)
+			}
+		}
+	}
+	if status := events[3].Result.Status; status != types.StatusFailed {
+		t.Errorf("failed event has status %s", status)
+	}
+
+	// Removed observers receive nothing
+	executor.ExecuteGraph(context.Background())
+	if len(events) != len(want) {
+		t.Errorf("observer received %d events after unsubscribing", len(events)-len(want))
+	}
+}
+
+func TestEventsChannel(t *testing.T) {
+	executor := NewExecutor(newTestGraph([]*funcNode{newFuncNode("a", nil)}))
+	events, cancel := executor.Events(10)
+	executor.ExecuteGraph(context.Background())
+	cancel()
+
+	var got []types.EventType
+	for event := range events {
+		got = append(got, event.Type)
+	}
+	want := []types.EventType{types.EventNodeStarted, types.EventNodeSucceeded, types.EventGraphFinished}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("channel delivered %v, want %v", got, want)
+	}
+}
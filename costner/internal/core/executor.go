@@ -9,119 +9,281 @@ import (
 	"costner/pkg/types"
 )
 
+// DefaultMaxConcurrency is the number of nodes an Executor runs at the same
+// time unless configured otherwise with SetMaxConcurrency.
+const DefaultMaxConcurrency = 4
+
+// Executor runs the nodes of a graph. Each run works on a snapshot of the
+// graph with its own results, so an Executor, or several Executors sharing
+// a graph, can run concurrently while the graph is being edited.
 type Executor struct {
-	graph   *Graph
-	results map[string]map[string]interface{}
-	mutex   sync.RWMutex
+	graph          *Graph
+	last           *runState
+	cache          map[string]cacheEntry
+	caching        bool
+	variables      map[string]interface{}
+	maxConcurrency int
+	keepGoing      bool
+	httpSettings   *types.HTTPSettings
+	events         eventHub
+	mutex          sync.RWMutex
 }
 
 func NewExecutor(graph *Graph) *Executor {
 	return &Executor{
-		graph:   graph,
-		results: make(map[string]map[string]interface{}),
+		graph:          graph,
+		cache:          make(map[string]cacheEntry),
+		maxConcurrency: DefaultMaxConcurrency,
 	}
 }
 
-func (e *Executor) ExecuteGraph(ctx context.Context) ([]types.ExecutionResult, error) {
+// SetMaxConcurrency limits how many nodes may execute at once. Values below
+// one are treated as one, which gives strictly sequential execution.
+func (e *Executor) SetMaxConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
+	e.maxConcurrency = n
+}
 
-	// Clear previous results
-	e.results = make(map[string]map[string]interface{})
+// SetKeepGoing makes ExecuteGraph continue after a node fails. Every node
+// that does not depend on a failed node still runs, and the transitive
+// dependents of failed nodes are reported as skipped.
+func (e *Executor) SetKeepGoing(keepGoing bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.keepGoing = keepGoing
+}
 
-	// Get execution order
-	order, err := e.graph.GetTopologicalOrder()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get execution order: %w", err)
+func (e *Executor) MaxConcurrency() int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.maxConcurrency
+}
+
+func (e *Executor) ExecuteGraph(ctx context.Context) ([]types.ExecutionResult, error) {
+	return e.executeSubset(ctx, nil)
+}
+
+// ExecuteUpTo runs a node together with everything it transitively depends
+// on, in topological order. Nodes that are not upstream of the target are
+// left out.
+func (e *Executor) ExecuteUpTo(ctx context.Context, nodeID string) ([]types.ExecutionResult, error) {
+	if _, exists := e.graph.GetNode(nodeID); !exists {
+		return nil, fmt.Errorf("%w: %s", types.ErrNodeNotFound, nodeID)
 	}
 
-	results := make([]types.ExecutionResult, 0, len(order))
+	subset := map[string]bool{nodeID: true}
+	for _, ancestor := range e.graph.GetAncestors(nodeID) {
+		subset[ancestor] = true
+	}
+	return e.executeSubset(ctx, subset)
+}
 
-	// Execute nodes in topological order
-	for _, nodeID := range order {
-		node, exists := e.graph.GetNode(nodeID)
-		if !exists {
-			return nil, fmt.Errorf("node not found during execution: %s", nodeID)
-		}
+// executeSubset runs the nodes in subset, or the whole graph when subset is
+// nil, as a fresh run.
+func (e *Executor) executeSubset(ctx context.Context, subset map[string]bool) ([]types.ExecutionResult, error) {
+	start := time.Now()
 
-		result, err := e.executeNode(ctx, node)
-		results = append(results, result)
+	// Snapshot the graph, which also yields the execution order
+	run, err := e.newRun()
+	if err != nil {
+		err = fmt.Errorf("failed to get execution order: %w", err)
+		e.emitFinished(start, nil, err)
+		return nil, err
+	}
 
-		if err != nil {
-			// Stop execution on error
-			return results, fmt.Errorf("execution stopped at node %s: %w", nodeID, err)
+	order := run.graph.order
+	if subset != nil {
+		order = make([]string, 0, len(subset))
+		for _, nodeID := range run.graph.order {
+			if subset[nodeID] {
+				order = append(order, nodeID)
+			}
 		}
 	}
 
-	return results, nil
+	ctx, closeTransport := e.withRunTransport(ctx)
+	defer closeTransport()
+
+	results, err := e.schedule(ctx, run, order)
+	e.setLast(run)
+	e.emitFinished(start, results, err)
+	return results, err
 }
 
-func (e *Executor) ExecuteNode(ctx context.Context, nodeID string) (types.ExecutionResult, error) {
+func (e *Executor) setLast(run *runState) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
+	e.last = run
+}
 
-	node, exists := e.graph.GetNode(nodeID)
-	if !exists {
+func (e *Executor) emitFinished(start time.Time, results []types.ExecutionResult, err error) {
+	event := types.ExecutionEvent{
+		Type:     types.EventGraphFinished,
+		Duration: time.Since(start),
+		Results:  results,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	e.emit(event)
+}
+
+// ExecuteNode runs a single node, taking its wired inputs from the outputs
+// of the previous run.
+func (e *Executor) ExecuteNode(ctx context.Context, nodeID string) (types.ExecutionResult, error) {
+	if _, exists := e.graph.GetNode(nodeID); !exists {
 		return types.ExecutionResult{}, types.ErrNodeNotFound
 	}
 
-	return e.executeNode(ctx, node)
+	run, err := e.newRun()
+	if err != nil {
+		return types.ExecutionResult{}, err
+	}
+	e.mutex.RLock()
+	last := e.last
+	e.mutex.RUnlock()
+	if last != nil {
+		run.seed(last)
+	}
+
+	ctx, closeTransport := e.withRunTransport(ctx)
+	defer closeTransport()
+
+	result, err := e.executeNode(ctx, run, nodeID)
+	e.setLast(run)
+	return result, err
+}
+
+func (e *Executor) executeNode(ctx context.Context, run *runState, nodeID string) (types.ExecutionResult, error) {
+	result, err := e.runNode(ctx, run, nodeID)
+	e.emitResult(result)
+	return result, err
 }
 
-func (e *Executor) executeNode(ctx context.Context, node types.Node) (types.ExecutionResult, error) {
+func (e *Executor) runNode(ctx context.Context, run *runState, nodeID string) (types.ExecutionResult, error) {
 	start := time.Now()
+	node := run.graph.nodes[nodeID]
 
 	result := types.ExecutionResult{
-		NodeID:    node.ID(),
+		NodeID:    nodeID,
 		Timestamp: start,
 	}
 
+	// Skip nodes fed only by untaken branches or failed upstream nodes
+	if skip := e.skipReason(run, nodeID); skip.reason != "" {
+		run.markSkipped(nodeID, skip)
+		result.Status = types.StatusSkipped
+		result.SkipReason = skip.reason
+		return result, nil
+	}
+
+	e.emit(types.ExecutionEvent{
+		Type:      types.EventNodeStarted,
+		NodeID:    nodeID,
+		Timestamp: start,
+	})
+
 	// Prepare inputs from connections
-	inputs, err := e.prepareInputs(node)
+	inputs, err := e.prepareInputs(run, nodeID)
 	if err != nil {
+		run.markFailed(nodeID)
+		result.Status = types.StatusFailed
 		result.Success = false
 		result.Error = err.Error()
 		result.Duration = time.Since(start)
 		return result, err
 	}
 
-	// Execute the node
-	outputs, err := node.Execute(ctx, inputs)
+	result.Inputs = inputs
+
+	// Reuse the previous outputs if nothing the node depends on has changed
+	hash := hashNodeState(node, inputs)
+	revision := run.graph.revisions[nodeID]
+	if outputs, ok := e.cachedOutputs(nodeID, revision, hash); ok {
+		run.setResult(nodeID, outputs)
+		result.Status = types.StatusSuccess
+		result.Success = true
+		result.Cached = true
+		result.Outputs = outputs
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	// Reject invalid execution settings before running anything
+	if err := ValidateNodeConfig(node.GetConfig()); err != nil {
+		run.markFailed(nodeID)
+		result.Status = types.StatusFailed
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	// Bound the node by its own timeout, if configured
+	timeout, _ := NodeTimeout(node.GetConfig())
+	nodeCtx := context.WithValue(ctx, nodeIDKey, nodeID)
+	nodeCtx = context.WithValue(nodeCtx, runKey, run)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		nodeCtx, cancel = context.WithTimeout(nodeCtx, timeout)
+		defer cancel()
+	}
+
+	// Execute the node, retrying according to its policy
+	outputs, err := e.executeWithRetry(nodeCtx, node, inputs, &result)
 	result.Duration = time.Since(start)
 
 	if err != nil {
+		run.markFailed(nodeID)
+		result.Status = types.StatusFailed
+		if isCancelled(ctx, err) {
+			result.Status = types.StatusCancelled
+		} else if isTimeout(err) {
+			result.Status = types.StatusTimedOut
+			err = timeoutError(ctx, timeout, err)
+		}
 		result.Success = false
 		result.Error = err.Error()
 		return result, err
 	}
 
 	// Store outputs for dependent nodes
-	e.results[node.ID()] = outputs
+	run.setResult(nodeID, outputs)
+	e.storeCache(nodeID, revision, hash, outputs)
+	result.Status = types.StatusSuccess
 	result.Success = true
 	result.Outputs = outputs
 
 	return result, nil
 }
 
-func (e *Executor) prepareInputs(node types.Node) (map[string]interface{}, error) {
+func (e *Executor) prepareInputs(run *runState, nodeID string) (map[string]interface{}, error) {
 	inputs := make(map[string]interface{})
 
 	// Get node's input definitions
-	nodeInputs := node.GetInputs()
+	nodeInputs := run.graph.inputs[nodeID]
 
-	// Set default values from node inputs
+	// Set default values from node inputs, filling in variables
 	for _, input := range nodeInputs {
 		if input.Value != nil {
-			inputs[input.Name] = input.Value
+			inputs[input.Name] = SubstituteVariables(input.Value, run.variables)
 		}
 	}
 
-	// Override with connected values
-	connections := e.graph.GetConnections()
-	for _, conn := range connections {
-		if conn.TargetNode == node.ID() {
+	// Override with connected values, gathering them per port in
+	// connection order
+	wired := make(map[string][]interface{})
+	for _, conn := range run.graph.connections {
+		if conn.TargetNode == nodeID {
+			if !e.connectionActive(run, conn) {
+				continue
+			}
+
 			// Get value from source node's output
-			sourceOutputs, exists := e.results[conn.SourceNode]
+			sourceOutputs, exists := run.result(conn.SourceNode)
 			if !exists {
 				if e.isInputRequired(nodeInputs, conn.TargetPort) {
 					return nil, fmt.Errorf("required input %s not available from %s", conn.TargetPort, conn.SourceNode)
@@ -137,15 +299,40 @@ func (e *Executor) prepareInputs(node types.Node) (map[string]interface{}, error
 				continue
 			}
 
-			inputs[conn.TargetPort] = value
+			wired[conn.TargetPort] = append(wired[conn.TargetPort], value)
+		}
+	}
+
+	for _, input := range nodeInputs {
+		values, exists := wired[input.Name]
+		if !exists {
+			continue
+		}
+		value, err := fanIn(input.Type, values)
+		if err != nil {
+			return nil, fmt.Errorf("node %s input %s: %w", nodeID, input.Name, err)
+		}
+		inputs[input.Name] = value
+	}
+
+	// Convert values to the declared port types
+	for _, input := range nodeInputs {
+		value, exists := inputs[input.Name]
+		if !exists {
+			continue
 		}
+		coerced, err := Coerce(value, input.Type)
+		if err != nil {
+			return nil, fmt.Errorf("node %s input %s: %w", nodeID, input.Name, err)
+		}
+		inputs[input.Name] = coerced
 	}
 
 	// Validate required inputs
 	for _, input := range nodeInputs {
 		if input.Required {
 			if _, exists := inputs[input.Name]; !exists {
-				return nil, fmt.Errorf("required input %s not provided for node %s", input.Name, node.ID())
+				return nil, fmt.Errorf("required input %s not provided for node %s", input.Name, nodeID)
 			}
 		}
 	}
@@ -162,15 +349,21 @@ func (e *Executor) isInputRequired(inputs []types.NodeInput, inputName string) b
 	return false
 }
 
+// GetNodeResult returns the outputs of a node from the most recently
+// finished run.
 func (e *Executor) GetNodeResult(nodeID string) (map[string]interface{}, bool) {
 	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-	result, exists := e.results[nodeID]
-	return result, exists
+	last := e.last
+	e.mutex.RUnlock()
+	if last == nil {
+		return nil, false
+	}
+	return last.result(nodeID)
 }
 
 func (e *Executor) ClearResults() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
-	e.results = make(map[string]map[string]interface{})
+	e.last = nil
+	e.cache = make(map[string]cacheEntry)
 }
\ No newline at end of file
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+
+	"costner/pkg/types"
+)
+
+// fanIn combines the values wired into one input, given in connection
+// order. List inputs always collect, whether they have one connection or
+// several: a value that is itself a list, including JSON array text such as
+// a response body, contributes its items, and any other value becomes a
+// single item. Map inputs merge the connected maps, with later connections
+// overriding earlier keys. Other inputs take exactly one value.
+func fanIn(portType types.PortType, values []interface{}) (interface{}, error) {
+	switch portType {
+	case types.PortList:
+		collected := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if list, err := coerceList(value); err == nil {
+				collected = append(collected, list.([]interface{})...)
+			} else {
+				collected = append(collected, value)
+			}
+		}
+		return collected, nil
+
+	case types.PortMap:
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		merged := make(map[string]interface{})
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+			m, err := coerceMap(value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot merge %T into a map input", value)
+			}
+			for key, v := range m.(map[string]interface{}) {
+				merged[key] = v
+			}
+		}
+		return merged, nil
+	}
+
+	if len(values) == 1 {
+		return values[0], nil
+	}
+	return nil, fmt.Errorf("%d connections into single-valued %s input", len(values), portType)
+}
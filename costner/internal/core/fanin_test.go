@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+func TestFanIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		portType types.PortType
+		values   []interface{}
+		want     interface{}
+		wantErr  bool
+	}{
+		{name: "single scalar into list", portType: types.PortList, values: []interface{}{1}, want: []interface{}{1}},
+		{name: "single list into list", portType: types.PortList, values: []interface{}{[]interface{}{1, 2}}, want: []interface{}{1, 2}},
+		{name: "single JSON array into list", portType: types.PortList, values: []interface{}{`["a","b"]`}, want: []interface{}{"a", "b"}},
+		{name: "collect scalars", portType: types.PortList, values: []interface{}{"a", "b"}, want: []interface{}{"a", "b"}},
+		{name: "flatten lists", portType: types.PortList, values: []interface{}{[]interface{}{1, 2}, 3, []string{"x"}}, want: []interface{}{1, 2, 3, "x"}},
+		{name: "plain string into list", portType: types.PortList, values: []interface{}{"abc"}, want: []interface{}{"abc"}},
+		{name: "single map", portType: types.PortMap, values: []interface{}{map[string]interface{}{"a": 1}}, want: map[string]interface{}{"a": 1}},
+		{
+			name:     "merge maps",
+			portType: types.PortMap,
+			values:   []interface{}{map[string]interface{}{"a": 1, "b": 1}, nil, `{"b":2}`, map[string]string{"c": "3"}},
+			want:     map[string]interface{}{"a": 1, "b": float64(2), "c": "3"},
+		},
+		{name: "merge non-map", portType: types.PortMap, values: []interface{}{map[string]interface{}{}, 5}, wantErr: true},
+		{name: "single scalar", portType: types.PortString, values: []interface{}{"x"}, want: "x"},
+		{name: "several into scalar", portType: types.PortString, values: []interface{}{"x", "y"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := fanIn(test.portType, test.values)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestListInputWithOneOrTwoConnections(t *testing.T) {
+	for _, sources := range [][]string{{"a"}, {"a", "b"}} {
+		var received interface{}
+		sink := newFuncNode("sink", func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+			received = inputs["items"]
+			return nil, nil
+		})
+		sink.Inputs = []types.NodeInput{{Name: "items", Type: types.PortList}}
+
+		graph := NewGraph()
+		graph.AddNode(sink)
+		for _, id := range sources {
+			graph.AddNode(newFuncNode(id, nil))
+			err := graph.AddConnection(types.Connection{ID: id, SourceNode: id, SourcePort: "out", TargetNode: "sink", TargetPort: "items"})
+			if err != nil {
+				t.Fatalf("connecting %s: %v", id, err)
+			}
+		}
+
+		if _, err := NewExecutor(graph).ExecuteGraph(context.Background()); err != nil {
+			t.Fatalf("%d connections: run failed: %v", len(sources), err)
+		}
+		want := make([]interface{}, len(sources))
+		for i, id := range sources {
+			want[i] = id
+		}
+		if !reflect.DeepEqual(received, want) {
+			t.Errorf("%d connections: sink received %#v, want %#v", len(sources), received, want)
+		}
+	}
+}
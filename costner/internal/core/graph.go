@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"costner/pkg/types"
@@ -9,28 +10,67 @@ import (
 
 type Graph struct {
 	nodes       map[string]types.Node
+	order       []string // node IDs in the order they were added
 	connections []types.Connection
+	revisions   map[string]uint64 // bumped whenever a node or its inputs change
+	revision    uint64
 	mutex       sync.RWMutex
+
+	// Change notification
+	pending        []types.GraphChange
+	observers      []graphObserverEntry
+	nextObserverID int
+	observerMutex  sync.Mutex
+	notifyMutex    sync.Mutex
 }
 
 func NewGraph() *Graph {
 	return &Graph{
 		nodes:       make(map[string]types.Node),
 		connections: make([]types.Connection, 0),
+		revisions:   make(map[string]uint64),
 	}
 }
 
 func (g *Graph) AddNode(node types.Node) {
+	defer g.flush()
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
+	if _, exists := g.nodes[node.ID()]; !exists {
+		g.order = append(g.order, node.ID())
+	}
 	g.nodes[node.ID()] = node
+	g.touch(node.ID())
+	g.queue(types.GraphChange{Type: types.ChangeNodeAdded, NodeID: node.ID(), Node: node})
+}
+
+// insertNode adds a node at a given position of the declaration order,
+// restoring a removed node where it was.
+func (g *Graph) insertNode(index int, node types.Node) {
+	defer g.flush()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if _, exists := g.nodes[node.ID()]; !exists {
+		if index < 0 || index > len(g.order) {
+			index = len(g.order)
+		}
+		g.order = append(g.order, "")
+		copy(g.order[index+1:], g.order[index:])
+		g.order[index] = node.ID()
+	}
+	g.nodes[node.ID()] = node
+	g.touch(node.ID())
+	g.queue(types.GraphChange{Type: types.ChangeNodeAdded, NodeID: node.ID(), Node: node})
 }
 
 func (g *Graph) RemoveNode(nodeID string) error {
+	defer g.flush()
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
 
-	if _, exists := g.nodes[nodeID]; !exists {
+	node, exists := g.nodes[nodeID]
+	if !exists {
 		return types.ErrNodeNotFound
 	}
 
@@ -39,14 +79,102 @@ func (g *Graph) RemoveNode(nodeID string) error {
 	for _, conn := range g.connections {
 		if conn.SourceNode != nodeID && conn.TargetNode != nodeID {
 			newConnections = append(newConnections, conn)
+			continue
 		}
+		if conn.SourceNode == nodeID {
+			g.touch(conn.TargetNode)
+		}
+		removed := conn
+		g.queue(types.GraphChange{Type: types.ChangeConnectionRemoved, Connection: &removed})
 	}
 	g.connections = newConnections
 
 	delete(g.nodes, nodeID)
+	delete(g.revisions, nodeID)
+	for i, id := range g.order {
+		if id == nodeID {
+			g.order = append(g.order[:i], g.order[i+1:]...)
+			break
+		}
+	}
+	g.queue(types.GraphChange{Type: types.ChangeNodeRemoved, NodeID: nodeID, Node: node})
+	return nil
+}
+
+// SetInputValue sets a static input value on a node and marks it changed so
+// the next execution does not reuse its cached result.
+func (g *Graph) SetInputValue(nodeID, name string, value interface{}) error {
+	defer g.flush()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	node, exists := g.nodes[nodeID]
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+	if err := node.SetInputValue(name, value); err != nil {
+		return err
+	}
+	g.touch(nodeID)
+	g.queue(types.GraphChange{Type: types.ChangeNodeUpdated, NodeID: nodeID, Node: node, Input: name})
+	return nil
+}
+
+func (g *Graph) SetConfigValue(nodeID, key string, value interface{}) error {
+	defer g.flush()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	node, exists := g.nodes[nodeID]
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+	node.SetConfigValue(key, value)
+	g.touch(nodeID)
+	g.queue(types.GraphChange{Type: types.ChangeNodeUpdated, NodeID: nodeID, Node: node})
+	return nil
+}
+
+// MoveNode sets the canvas position of a node. Positions do not affect
+// execution, so the node is not marked changed.
+func (g *Graph) MoveNode(nodeID string, position types.Position) error {
+	defer g.flush()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	node, exists := g.nodes[nodeID]
+	if !exists {
+		return types.ErrNodeNotFound
+	}
+	node.SetPosition(position)
+	g.queue(types.GraphChange{Type: types.ChangeNodeUpdated, NodeID: nodeID, Node: node})
 	return nil
 }
 
+// MarkDirty forces a node to be re-executed on the next run.
+func (g *Graph) MarkDirty(nodeID string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if _, exists := g.nodes[nodeID]; exists {
+		g.touch(nodeID)
+	}
+}
+
+// Revision returns a number that changes whenever the node, its inputs or
+// its connections change. Cached results are only reused while the revision
+// they were computed at is current.
+func (g *Graph) Revision(nodeID string) uint64 {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.revisions[nodeID]
+}
+
+// touch records a change of a node. It must be called with the graph locked.
+func (g *Graph) touch(nodeID string) {
+	g.revision++
+	g.revisions[nodeID] = g.revision
+}
+
 func (g *Graph) GetNode(nodeID string) (types.Node, bool) {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
@@ -65,7 +193,21 @@ func (g *Graph) GetAllNodes() map[string]types.Node {
 	return result
 }
 
+// GetNodesInOrder returns the nodes in the order they were added to the
+// graph, which for loaded projects is their declaration order.
+func (g *Graph) GetNodesInOrder() []types.Node {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	result := make([]types.Node, 0, len(g.order))
+	for _, nodeID := range g.order {
+		result = append(result, g.nodes[nodeID])
+	}
+	return result
+}
+
 func (g *Graph) AddConnection(conn types.Connection) error {
+	defer g.flush()
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
 
@@ -80,22 +222,44 @@ func (g *Graph) AddConnection(conn types.Connection) error {
 	}
 
 	g.connections = append(g.connections, conn)
+	g.touch(conn.TargetNode)
+	g.queue(types.GraphChange{Type: types.ChangeConnectionAdded, Connection: &conn})
 	return nil
 }
 
 func (g *Graph) RemoveConnection(connectionID string) error {
+	defer g.flush()
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
 
 	for i, conn := range g.connections {
 		if conn.ID == connectionID {
 			g.connections = append(g.connections[:i], g.connections[i+1:]...)
+			g.touch(conn.TargetNode)
+			g.queue(types.GraphChange{Type: types.ChangeConnectionRemoved, Connection: &conn})
 			return nil
 		}
 	}
 	return fmt.Errorf("connection not found: %s", connectionID)
 }
 
+// insertConnection puts a connection back at its former index without
+// validating it, restoring the fan-in order of its target port.
+func (g *Graph) insertConnection(index int, conn types.Connection) {
+	defer g.flush()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if index < 0 || index > len(g.connections) {
+		index = len(g.connections)
+	}
+	g.connections = append(g.connections, types.Connection{})
+	copy(g.connections[index+1:], g.connections[index:])
+	g.connections[index] = conn
+	g.touch(conn.TargetNode)
+	g.queue(types.GraphChange{Type: types.ChangeConnectionAdded, Connection: &conn})
+}
+
 func (g *Graph) GetConnections() []types.Connection {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
@@ -105,6 +269,15 @@ func (g *Graph) GetConnections() []types.Connection {
 	return result
 }
 
+// ValidateConnection checks that a connection could be added to the graph:
+// both ports must exist, their types must be compatible and the input must
+// accept another connection.
+func (g *Graph) ValidateConnection(conn types.Connection) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.validateConnection(conn)
+}
+
 func (g *Graph) validateConnection(conn types.Connection) error {
 	// Check if source and target nodes exist
 	sourceNode, exists := g.nodes[conn.SourceNode]
@@ -120,9 +293,11 @@ func (g *Graph) validateConnection(conn types.Connection) error {
 	// Check if source port exists
 	sourceOutputs := sourceNode.GetOutputs()
 	sourcePortExists := false
+	var sourceType types.PortType
 	for _, output := range sourceOutputs {
 		if output.Name == conn.SourcePort {
 			sourcePortExists = true
+			sourceType = output.Type
 			break
 		}
 	}
@@ -133,9 +308,11 @@ func (g *Graph) validateConnection(conn types.Connection) error {
 	// Check if target port exists
 	targetInputs := targetNode.GetInputs()
 	targetPortExists := false
+	var targetType types.PortType
 	for _, input := range targetInputs {
 		if input.Name == conn.TargetPort {
 			targetPortExists = true
+			targetType = input.Type
 			break
 		}
 	}
@@ -143,6 +320,37 @@ func (g *Graph) validateConnection(conn types.Connection) error {
 		return fmt.Errorf("target port not found: %s.%s", conn.TargetNode, conn.TargetPort)
 	}
 
+	// Check that the port types are compatible
+	if _, err := types.ParsePortType(string(sourceType)); err != nil {
+		return fmt.Errorf("%s.%s: %w", conn.SourceNode, conn.SourcePort, err)
+	}
+	if _, err := types.ParsePortType(string(targetType)); err != nil {
+		return fmt.Errorf("%s.%s: %w", conn.TargetNode, conn.TargetPort, err)
+	}
+	if !types.CanConnect(sourceType, targetType) {
+		return fmt.Errorf("%w: %s.%s (%s) cannot be connected to %s.%s (%s)",
+			types.ErrIncompatiblePorts, conn.SourceNode, conn.SourcePort, sourceType,
+			conn.TargetNode, conn.TargetPort, targetType)
+	}
+
+	// Reject duplicates and a second connection into a single-valued input
+	for _, existing := range g.connections {
+		if existing.ID == conn.ID {
+			return fmt.Errorf("%w: connection %s already exists", types.ErrInvalidConnection, conn.ID)
+		}
+		if existing.TargetNode != conn.TargetNode || existing.TargetPort != conn.TargetPort {
+			continue
+		}
+		if existing.SourceNode == conn.SourceNode && existing.SourcePort == conn.SourcePort {
+			return fmt.Errorf("%w: %s.%s is already connected to %s.%s", types.ErrInvalidConnection,
+				conn.SourceNode, conn.SourcePort, conn.TargetNode, conn.TargetPort)
+		}
+		if !targetType.AcceptsMultiple() {
+			return fmt.Errorf("%w: input %s.%s (%s) already has a connection from %s.%s", types.ErrInvalidConnection,
+				conn.TargetNode, conn.TargetPort, targetType, existing.SourceNode, existing.SourcePort)
+		}
+	}
+
 	return nil
 }
 
@@ -194,7 +402,11 @@ func (g *Graph) hasCycleDFS(nodeID string, adjList map[string][]string, visited,
 func (g *Graph) GetTopologicalOrder() ([]string, error) {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
+	return g.topologicalOrder()
+}
 
+// topologicalOrder must be called with the graph locked.
+func (g *Graph) topologicalOrder() ([]string, error) {
 	// Create adjacency list and in-degree count
 	adjList := make(map[string][]string)
 	inDegree := make(map[string]int)
@@ -211,16 +423,21 @@ func (g *Graph) GetTopologicalOrder() ([]string, error) {
 		inDegree[conn.TargetNode]++
 	}
 
-	// Kahn's algorithm for topological sorting
+	// Kahn's algorithm for topological sorting, always taking the ready
+	// node with the lowest rank so that the order is reproducible
+	ranks := g.ranks()
 	queue := make([]string, 0)
-	for nodeID, degree := range inDegree {
-		if degree == 0 {
+	for _, nodeID := range g.order {
+		if inDegree[nodeID] == 0 {
 			queue = append(queue, nodeID)
 		}
 	}
 
 	result := make([]string, 0)
 	for len(queue) > 0 {
+		sort.Slice(queue, func(i, j int) bool {
+			return ranks[queue[i]].before(ranks[queue[j]])
+		})
 		current := queue[0]
 		queue = queue[1:]
 		result = append(result, current)
@@ -254,6 +471,29 @@ func (g *Graph) GetDependencies(nodeID string) []string {
 	return dependencies
 }
 
+// GetAncestors returns every node the given node transitively depends on.
+func (g *Graph) GetAncestors(nodeID string) []string {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	visited := make(map[string]bool)
+	ancestors := make([]string, 0)
+	stack := []string{nodeID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, conn := range g.connections {
+			if conn.TargetNode == current && !visited[conn.SourceNode] {
+				visited[conn.SourceNode] = true
+				ancestors = append(ancestors, conn.SourceNode)
+				stack = append(stack, conn.SourceNode)
+			}
+		}
+	}
+	return ancestors
+}
+
 func (g *Graph) GetDependents(nodeID string) []string {
 	g.mutex.RLock()
 	defer g.mutex.RUnlock()
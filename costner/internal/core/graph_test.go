@@ -0,0 +1,63 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// typedNode has one input and one output of each of the given types, named
+// after the type.
+func typedNode(id string, portTypes ...types.PortType) *funcNode {
+	node := newFuncNode(id, nil)
+	node.Inputs = nil
+	node.Outputs = nil
+	for _, portType := range portTypes {
+		node.Inputs = append(node.Inputs, types.NodeInput{Name: string(portType), Type: portType})
+		node.Outputs = append(node.Outputs, types.NodeOutput{Name: string(portType), Type: portType})
+	}
+	return node
+}
+
+func TestConnectionValidation(t *testing.T) {
+	all := []types.PortType{types.PortAny, types.PortJSON, types.PortString, types.PortInt, types.PortFloat, types.PortMap, types.PortList}
+	graph := NewGraph()
+	for _, id := range []string{"a", "b", "c"} {
+		graph.AddNode(typedNode(id, all...))
+	}
+
+	tests := []struct {
+		name    string
+		conn    types.Connection
+		wantErr error
+	}{
+		{name: "int into float", conn: types.Connection{ID: "1", SourceNode: "a", SourcePort: "int", TargetNode: "b", TargetPort: "float"}},
+		{name: "any into map", conn: types.Connection{ID: "2", SourceNode: "a", SourcePort: "any", TargetNode: "b", TargetPort: "map"}},
+		{name: "list into json", conn: types.Connection{ID: "3", SourceNode: "a", SourcePort: "list", TargetNode: "b", TargetPort: "json"}},
+		{name: "float into int", conn: types.Connection{ID: "4", SourceNode: "a", SourcePort: "float", TargetNode: "b", TargetPort: "int"}, wantErr: types.ErrIncompatiblePorts},
+		{name: "json into string", conn: types.Connection{ID: "5", SourceNode: "a", SourcePort: "json", TargetNode: "b", TargetPort: "string"}, wantErr: types.ErrIncompatiblePorts},
+		{name: "map into list", conn: types.Connection{ID: "6", SourceNode: "a", SourcePort: "map", TargetNode: "b", TargetPort: "list"}, wantErr: types.ErrIncompatiblePorts},
+		{name: "second connection into list", conn: types.Connection{ID: "7", SourceNode: "c", SourcePort: "list", TargetNode: "b", TargetPort: "list"}},
+		{name: "third connection into list", conn: types.Connection{ID: "8", SourceNode: "a", SourcePort: "list", TargetNode: "b", TargetPort: "list"}},
+		{name: "second connection into map", conn: types.Connection{ID: "9", SourceNode: "c", SourcePort: "map", TargetNode: "b", TargetPort: "map"}},
+		{name: "second connection into float", conn: types.Connection{ID: "10", SourceNode: "c", SourcePort: "float", TargetNode: "b", TargetPort: "float"}, wantErr: types.ErrInvalidConnection},
+		{name: "duplicate", conn: types.Connection{ID: "11", SourceNode: "a", SourcePort: "list", TargetNode: "b", TargetPort: "list"}, wantErr: types.ErrInvalidConnection},
+		{name: "duplicate ID", conn: types.Connection{ID: "1", SourceNode: "c", SourcePort: "string", TargetNode: "b", TargetPort: "string"}, wantErr: types.ErrInvalidConnection},
+	}
+
+	for _, test := range tests {
+		err := graph.AddConnection(test.conn)
+		if test.wantErr == nil && err != nil {
+			t.Errorf("%s: rejected: %v", test.name, err)
+		}
+		if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.wantErr)
+		}
+	}
+
+	// Rejected connections leave no trace
+	if got := len(graph.GetConnections()); got != 6 {
+		t.Errorf("graph has %d connections, want 6", got)
+	}
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"costner/pkg/types"
+)
+
+// DefaultHistoryLimit is the number of undo steps kept by a History.
+const DefaultHistoryLimit = 100
+
+// History applies commands to a graph and records them for undo and redo.
+// All edits made through a History, whether from the GUI or another
+// front end, share one undo stack.
+type History struct {
+	graph *Graph
+	undo  []Command
+	redo  []Command
+	tx    *transaction
+	depth int
+	limit int
+	mutex sync.Mutex
+}
+
+func NewHistory(graph *Graph) *History {
+	return &History{
+		graph: graph,
+		limit: DefaultHistoryLimit,
+	}
+}
+
+// Graph returns the graph the history edits.
+func (h *History) Graph() *Graph {
+	return h.graph
+}
+
+func (h *History) SetLimit(limit int) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.limit = limit
+	h.trim()
+}
+
+// Execute applies a command and records it. Inside a transaction the
+// command becomes part of the transaction.
+func (h *History) Execute(cmd Command) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := cmd.Do(h.graph); err != nil {
+		return err
+	}
+
+	if h.tx != nil {
+		h.tx.commands = h.record(h.tx.commands, cmd)
+		return nil
+	}
+
+	h.undo = h.record(h.undo, cmd)
+	h.redo = nil
+	h.trim()
+	return nil
+}
+
+func (h *History) record(stack []Command, cmd Command) []Command {
+	if len(stack) > 0 {
+		if last, ok := stack[len(stack)-1].(mergeable); ok && last.merge(cmd) {
+			return stack
+		}
+	}
+	return append(stack, cmd)
+}
+
+func (h *History) trim() {
+	if h.limit > 0 && len(h.undo) > h.limit {
+		h.undo = h.undo[len(h.undo)-h.limit:]
+	}
+}
+
+// Begin starts a transaction. Commands executed until the matching Commit
+// are undone and redone as one step. Transactions may be nested; only the
+// outermost one is recorded.
+func (h *History) Begin(description string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.depth == 0 {
+		h.tx = &transaction{description: description}
+	}
+	h.depth++
+}
+
+func (h *History) Commit() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.depth == 0 {
+		return fmt.Errorf("no transaction in progress")
+	}
+	h.depth--
+	if h.depth > 0 {
+		return nil
+	}
+
+	tx := h.tx
+	h.tx = nil
+	if len(tx.commands) > 0 {
+		h.undo = append(h.undo, tx)
+		h.redo = nil
+		h.trim()
+	}
+	return nil
+}
+
+// Rollback undoes every command of the current transaction and ends it.
+func (h *History) Rollback() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.depth == 0 {
+		return fmt.Errorf("no transaction in progress")
+	}
+	tx := h.tx
+	h.tx = nil
+	h.depth = 0
+	return tx.Undo(h.graph)
+}
+
+// Transaction runs fn inside a transaction, rolling it back if fn fails.
+func (h *History) Transaction(description string, fn func() error) error {
+	h.Begin(description)
+	if err := fn(); err != nil {
+		if rollbackErr := h.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	return h.Commit()
+}
+
+func (h *History) Undo() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.tx != nil {
+		return fmt.Errorf("cannot undo during a transaction")
+	}
+	if len(h.undo) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+
+	cmd := h.undo[len(h.undo)-1]
+	if err := cmd.Undo(h.graph); err != nil {
+		return fmt.Errorf("undo %s: %w", cmd.Description(), err)
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, cmd)
+	return nil
+}
+
+func (h *History) Redo() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.tx != nil {
+		return fmt.Errorf("cannot redo during a transaction")
+	}
+	if len(h.redo) == 0 {
+		return fmt.Errorf("nothing to redo")
+	}
+
+	cmd := h.redo[len(h.redo)-1]
+	if err := cmd.Do(h.graph); err != nil {
+		return fmt.Errorf("redo %s: %w", cmd.Description(), err)
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, cmd)
+	return nil
+}
+
+func (h *History) CanUndo() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.undo) > 0 && h.tx == nil
+}
+
+func (h *History) CanRedo() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.redo) > 0 && h.tx == nil
+}
+
+// Clear forgets all recorded commands, e.g. after loading a project.
+func (h *History) Clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.undo = nil
+	h.redo = nil
+}
+
+func (h *History) AddNode(node types.Node) error {
+	return h.Execute(AddNodeCommand(node))
+}
+
+func (h *History) RemoveNode(nodeID string) error {
+	return h.Execute(RemoveNodeCommand(nodeID))
+}
+
+func (h *History) AddConnection(conn types.Connection) error {
+	return h.Execute(AddConnectionCommand(conn))
+}
+
+func (h *History) RemoveConnection(connectionID string) error {
+	return h.Execute(RemoveConnectionCommand(connectionID))
+}
+
+func (h *History) SetInputValue(nodeID, name string, value interface{}) error {
+	return h.Execute(SetInputValueCommand(nodeID, name, value))
+}
+
+func (h *History) MoveNode(nodeID string, position types.Position) error {
+	return h.Execute(MoveNodeCommand(nodeID, position))
+}
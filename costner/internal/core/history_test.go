@@ -0,0 +1,190 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+func inputValue(t *testing.T, graph *Graph, nodeID, name string) interface{} {
+	t.Helper()
+	node, exists := graph.GetNode(nodeID)
+	if !exists {
+		t.Fatalf("node %s not found", nodeID)
+	}
+	for _, input := range node.GetInputs() {
+		if input.Name == name {
+			return input.Value
+		}
+	}
+	t.Fatalf("input %s.%s not found", nodeID, name)
+	return nil
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	graph := NewGraph()
+	history := NewHistory(graph)
+
+	steps := []struct {
+		name  string
+		apply func() error
+		check func() bool
+	}{
+		{"add a", func() error { return history.AddNode(newFuncNode("a", nil)) }, func() bool { _, ok := graph.GetNode("a"); return ok }},
+		{"add b", func() error { return history.AddNode(newFuncNode("b", nil)) }, func() bool { _, ok := graph.GetNode("b"); return ok }},
+		{"connect", func() error {
+			return history.AddConnection(types.Connection{ID: "c1", SourceNode: "a", SourcePort: "out", TargetNode: "b", TargetPort: "in"})
+		}, func() bool { return len(graph.GetConnections()) == 1 }},
+		{"move", func() error { return history.MoveNode("a", types.Position{X: 10, Y: 20}) }, func() bool {
+			node, _ := graph.GetNode("a")
+			return node.GetPosition() == types.Position{X: 10, Y: 20}
+		}},
+		{"remove a", func() error { return history.RemoveNode("a") }, func() bool {
+			_, ok := graph.GetNode("a")
+			return !ok && len(graph.GetConnections()) == 0
+		}},
+	}
+
+	for _, step := range steps {
+		if err := step.apply(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if !step.check() {
+			t.Fatalf("%s was not applied", step.name)
+		}
+	}
+
+	// Undo everything, checking that each step is reverted in turn
+	for i := len(steps) - 1; i >= 0; i-- {
+		if err := history.Undo(); err != nil {
+			t.Fatalf("undo %s: %v", steps[i].name, err)
+		}
+		if i > 0 && !steps[i-1].check() {
+			t.Fatalf("undoing %s also reverted %s", steps[i].name, steps[i-1].name)
+		}
+	}
+	if len(graph.GetAllNodes()) != 0 || history.CanUndo() {
+		t.Fatal("graph is not empty after undoing everything")
+	}
+
+	// Redo everything
+	for _, step := range steps {
+		if err := history.Redo(); err != nil {
+			t.Fatalf("redo %s: %v", step.name, err)
+		}
+		if !step.check() {
+			t.Fatalf("%s was not redone", step.name)
+		}
+	}
+
+	// The removed node comes back with its connection and position
+	if err := history.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.GetConnections()) != 1 || !steps[3].check() {
+		t.Error("undoing the removal did not restore the connection and position")
+	}
+
+	// A new edit discards the redo stack
+	if err := history.SetInputValue("a", "in", 1); err != nil {
+		t.Fatal(err)
+	}
+	if history.CanRedo() {
+		t.Error("redo is still possible after a new edit")
+	}
+}
+
+func TestHistoryMergesInputEdits(t *testing.T) {
+	graph := NewGraph()
+	graph.AddNode(newFuncNode("a", nil))
+	history := NewHistory(graph)
+
+	for _, value := range []string{"h", "he", "hello"} {
+		if err := history.SetInputValue("a", "in", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := history.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if value := inputValue(t, graph, "a", "in"); value != nil {
+		t.Errorf("input is %v after one undo, want the edits undone together", value)
+	}
+	if err := history.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if value := inputValue(t, graph, "a", "in"); value != "hello" {
+		t.Errorf("input is %v after redo, want hello", value)
+	}
+}
+
+func TestHistoryTransaction(t *testing.T) {
+	graph := NewGraph()
+	history := NewHistory(graph)
+
+	err := history.Transaction("add two", func() error {
+		if err := history.AddNode(newFuncNode("a", nil)); err != nil {
+			return err
+		}
+		return history.AddNode(newFuncNode("b", nil))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.GetAllNodes()) != 0 {
+		t.Error("undo did not revert the whole transaction")
+	}
+	if err := history.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.GetAllNodes()) != 2 {
+		t.Error("redo did not reapply the whole transaction")
+	}
+
+	// A failing transaction is rolled back and not recorded
+	failure := errors.New("failed")
+	err = history.Transaction("add and fail", func() error {
+		if err := history.AddNode(newFuncNode("c", nil)); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want the transaction error", err)
+	}
+	if _, exists := graph.GetNode("c"); exists {
+		t.Error("failed transaction was not rolled back")
+	}
+	if err := history.Undo(); err != nil || len(graph.GetAllNodes()) != 0 {
+		t.Error("the failed transaction was recorded as an undo step")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	graph := NewGraph()
+	history := NewHistory(graph)
+	history.SetLimit(2)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := history.AddNode(newFuncNode(id, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	undone := 0
+	for history.CanUndo() {
+		if err := history.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		undone++
+	}
+	if undone != 2 {
+		t.Errorf("undid %d steps, want 2", undone)
+	}
+	if _, exists := graph.GetNode("a"); !exists {
+		t.Error("the oldest step was undone beyond the limit")
+	}
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// LoadOptions configures a load test. Users virtual users each run the graph
+// in a loop until Duration has passed. With a RampUp the users are started
+// evenly spread over that period instead of all at once.
+type LoadOptions struct {
+	Users    int
+	Duration time.Duration
+	RampUp   time.Duration
+}
+
+// loadStats collects node latencies from concurrent iterations.
+type loadStats struct {
+	order      []string
+	latencies  map[string][]time.Duration
+	waits      map[string]time.Duration
+	errors     map[string]int
+	iterations int
+	failed     int
+	mutex      sync.Mutex
+}
+
+// Load runs the graph repeatedly from several virtual users at once and
+// reports per-node throughput, error rate and latency percentiles. The
+// latency of a node is the request duration it reports in its duration
+// output, which excludes time spent waiting on host limits, or its
+// execution time when it has none. Iterations in progress when the duration
+// ends are allowed to finish; iterations interrupted by cancelling ctx are
+// left out of the report. Caching should stay disabled, otherwise unchanged
+// nodes are only executed once.
+func (e *Executor) Load(ctx context.Context, opts LoadOptions) (*types.LoadReport, error) {
+	if opts.Users < 1 {
+		return nil, fmt.Errorf("load test needs at least one user")
+	}
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("load test needs a positive duration")
+	}
+
+	// Fail early on graphs that cannot run at all
+	run, err := e.newRun()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution order: %w", err)
+	}
+
+	stats := &loadStats{
+		order:     run.graph.order,
+		latencies: make(map[string][]time.Duration),
+		waits:     make(map[string]time.Duration),
+		errors:    make(map[string]int),
+	}
+
+	// All users share one transport, so host limits apply to the total load
+	ctx, closeTransport := e.withRunTransport(ctx)
+	defer closeTransport()
+
+	start := time.Now()
+	end := start.Add(opts.Duration)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Users; i++ {
+		delay := opts.RampUp * time.Duration(i) / time.Duration(opts.Users)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.loadUser(ctx, delay, end, stats)
+		}()
+	}
+	wg.Wait()
+
+	return stats.report(opts.Users, time.Since(start)), nil
+}
+
+// loadUser is one virtual user, starting iterations until end.
+func (e *Executor) loadUser(ctx context.Context, delay time.Duration, end time.Time, stats *loadStats) {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	for ctx.Err() == nil && time.Now().Before(end) {
+		run, err := e.newRun()
+		if err != nil {
+			stats.addIteration(nil, err)
+			return
+		}
+		results, err := e.schedule(ctx, run, run.graph.order)
+
+		// An iteration interrupted by cancellation says nothing about the
+		// system under test
+		if ctx.Err() != nil {
+			return
+		}
+		stats.addIteration(results, err)
+	}
+}
+
+func (s *loadStats) addIteration(results []types.ExecutionResult, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.iterations++
+	if err != nil {
+		s.failed++
+	}
+	for _, result := range results {
+		if result.Status == types.StatusSkipped {
+			continue
+		}
+		latency := result.Duration
+		if duration, ok := result.Outputs["duration"].(time.Duration); ok {
+			latency = duration
+		}
+		s.latencies[result.NodeID] = append(s.latencies[result.NodeID], latency)
+		if wait, ok := result.Outputs["wait"].(time.Duration); ok {
+			s.waits[result.NodeID] += wait
+		}
+		if !result.Success {
+			s.errors[result.NodeID]++
+		}
+	}
+}
+
+func (s *loadStats) report(users int, elapsed time.Duration) *types.LoadReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	report := &types.LoadReport{
+		Users:            users,
+		Elapsed:          elapsed,
+		Iterations:       s.iterations,
+		FailedIterations: s.failed,
+	}
+	for _, nodeID := range s.order {
+		latencies := s.latencies[nodeID]
+		if len(latencies) == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		report.Nodes = append(report.Nodes, types.NodeLoadStats{
+			NodeID:     nodeID,
+			Executions: len(latencies),
+			Errors:     s.errors[nodeID],
+			Throughput: float64(len(latencies)) / elapsed.Seconds(),
+			ErrorRate:  float64(s.errors[nodeID]) / float64(len(latencies)),
+			Min:        latencies[0],
+			P50:        percentile(latencies, 50),
+			P90:        percentile(latencies, 90),
+			P99:        percentile(latencies, 99),
+			Max:        latencies[len(latencies)-1],
+			AvgWait:    s.waits[nodeID] / time.Duration(len(latencies)),
+		})
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
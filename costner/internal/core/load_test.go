@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loadServer answers after delay and fails every fourth request.
+type loadServer struct {
+	*httptest.Server
+	start    time.Time
+	arrivals []time.Duration
+	failures int
+	mutex    sync.Mutex
+}
+
+func newLoadServer(delay time.Duration) *loadServer {
+	s := &loadServer{start: time.Now()}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.arrivals = append(s.arrivals, time.Since(s.start))
+		fail := len(s.arrivals)%4 == 0
+		if fail {
+			s.failures++
+		}
+		s.mutex.Unlock()
+
+		time.Sleep(delay)
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	return s
+}
+
+// requestNode sends a GET to url through the run's transport and reports
+// the request duration like a request node.
+func requestNode(id, url string) *funcNode {
+	return newFuncNode(id, func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		resp, err := (&http.Client{Transport: Transport(ctx)}).Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return nil, fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return map[string]interface{}{"out": resp.StatusCode, "duration": time.Since(start)}, nil
+	})
+}
+
+func TestLoad(t *testing.T) {
+	const delay = 10 * time.Millisecond
+	server := newLoadServer(delay)
+	defer server.Close()
+
+	graph := newTestGraph([]*funcNode{requestNode("get", server.URL), newFuncNode("after", nil)}, [2]string{"get", "after"})
+	report, err := NewExecutor(graph).Load(context.Background(), LoadOptions{Users: 3, Duration: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	server.mutex.Lock()
+	requests, failures := len(server.arrivals), server.failures
+	server.mutex.Unlock()
+
+	if report.Iterations != requests {
+		t.Errorf("%d iterations for %d requests", report.Iterations, requests)
+	}
+	if report.FailedIterations != failures {
+		t.Errorf("%d failed iterations for %d failed requests", report.FailedIterations, failures)
+	}
+	if len(report.Nodes) != 2 || report.Nodes[0].NodeID != "get" || report.Nodes[1].NodeID != "after" {
+		t.Fatalf("report nodes %+v, want get and after in execution order", report.Nodes)
+	}
+
+	get := report.Nodes[0]
+	if get.Executions != requests || get.Errors != failures {
+		t.Errorf("get: %d executions with %d errors, want %d with %d", get.Executions, get.Errors, requests, failures)
+	}
+	if want := float64(failures) / float64(requests); get.ErrorRate != want {
+		t.Errorf("get: error rate %v, want %v", get.ErrorRate, want)
+	}
+	if get.Min < delay {
+		t.Errorf("get: minimum latency %v is below the server delay %v", get.Min, delay)
+	}
+	if !(get.Min <= get.P50 && get.P50 <= get.P90 && get.P90 <= get.P99 && get.P99 <= get.Max) {
+		t.Errorf("get: percentiles out of order: %+v", get)
+	}
+
+	// after is skipped whenever get fails, and skips are not counted
+	if after := report.Nodes[1]; after.Executions != requests-failures || after.Errors != 0 {
+		t.Errorf("after: %d executions with %d errors, want %d without errors", after.Executions, after.Errors, requests-failures)
+	}
+}
+
+func TestLoadRampUp(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	for _, rampUp := range []time.Duration{0, 200 * time.Millisecond} {
+		server := newLoadServer(delay)
+		graph := newTestGraph([]*funcNode{requestNode("get", server.URL)})
+		_, err := NewExecutor(graph).Load(context.Background(), LoadOptions{Users: 4, Duration: 250 * time.Millisecond, RampUp: rampUp})
+		server.Close()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		// Each request takes delay, so requests arriving before that come
+		// from users that started at once
+		early := 0
+		for _, arrival := range server.arrivals {
+			if arrival < delay*4/5 {
+				early++
+			}
+		}
+		want := 4
+		if rampUp > 0 {
+			want = 1
+		}
+		if early != want {
+			t.Errorf("ramp-up %v: %d users started at once, want %d", rampUp, early, want)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 10)
+	for i := range sorted {
+		sorted[i] = time.Duration(i + 1)
+	}
+	for p, want := range map[int]time.Duration{0: 1, 50: 5, 90: 9, 91: 10, 99: 10, 100: 10} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%d = %d, want %d", p, got, want)
+		}
+	}
+}
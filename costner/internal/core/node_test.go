@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+
+	"costner/pkg/types"
+)
+
+// funcNode is a node for tests that runs fn, or outputs its own ID when fn
+// is nil. It has an optional "in" input and an "out" output.
+type funcNode struct {
+	types.BaseNode
+	fn func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error)
+}
+
+func newFuncNode(id string, fn func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error)) *funcNode {
+	return &funcNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: "func",
+			NodeName: id,
+			Inputs:   []types.NodeInput{{Name: "in", Type: "any"}},
+			Outputs:  []types.NodeOutput{{Name: "out", Type: "any"}},
+			Config:   make(map[string]interface{}),
+		},
+		fn: fn,
+	}
+}
+
+func (n *funcNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	if n.fn == nil {
+		return map[string]interface{}{"out": n.NodeID}, nil
+	}
+	return n.fn(ctx, inputs)
+}
+
+func (n *funcNode) Clone() types.Node {
+	clone := *n
+	clone.Inputs = append([]types.NodeInput(nil), n.Inputs...)
+	clone.Outputs = append([]types.NodeOutput(nil), n.Outputs...)
+	clone.Config = make(map[string]interface{}, len(n.Config))
+	for key, value := range n.Config {
+		clone.Config[key] = value
+	}
+	return &clone
+}
+
+// newTestGraph adds nodes to a new graph and connects the "out" port of
+// each source to the "in" port of its target, given as source/target pairs.
+func newTestGraph(nodes []*funcNode, edges ...[2]string) *Graph {
+	graph := NewGraph()
+	for _, node := range nodes {
+		graph.AddNode(node)
+	}
+	for i, edge := range edges {
+		graph.AddConnection(types.Connection{
+			ID:         edge[0] + "-" + edge[1] + "-" + string(rune('a'+i)),
+			SourceNode: edge[0],
+			SourcePort: "out",
+			TargetNode: edge[1],
+			TargetPort: "in",
+		})
+	}
+	return graph
+}
@@ -0,0 +1,53 @@
+package core
+
+import "fmt"
+
+// NodeOrder reads the optional sequencing hint of a node from its config.
+// "order" sorts ascending; "priority" is the opposite, so higher priorities
+// come first. Nodes without either have order 0.
+func NodeOrder(config map[string]interface{}) (int, error) {
+	if value, exists := config["order"]; exists && value != nil {
+		order, err := parseInt(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid order: %w", err)
+		}
+		return order, nil
+	}
+	if value, exists := config["priority"]; exists && value != nil {
+		priority, err := parseInt(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid priority: %w", err)
+		}
+		return -priority, nil
+	}
+	return 0, nil
+}
+
+// nodeRank is the sort key deciding which of several ready nodes runs
+// first: explicit order, then declaration order.
+type nodeRank struct {
+	order int
+	index int
+}
+
+func (r nodeRank) before(other nodeRank) bool {
+	if r.order != other.order {
+		return r.order < other.order
+	}
+	return r.index < other.index
+}
+
+// ranks computes the rank of every node. It must be called with the graph
+// locked. Nodes with an invalid order are ranked as order 0 here and fail
+// when they are executed.
+func (g *Graph) ranks() map[string]nodeRank {
+	ranks := make(map[string]nodeRank, len(g.nodes))
+	for index, nodeID := range g.order {
+		order, _ := NodeOrder(g.nodes[nodeID].GetConfig())
+		ranks[nodeID] = nodeRank{
+			order: order,
+			index: index,
+		}
+	}
+	return ranks
+}
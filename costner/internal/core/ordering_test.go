@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+func TestNodeOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    int
+		wantErr string
+	}{
+		{name: "default", config: map[string]interface{}{}, want: 0},
+		{name: "order", config: map[string]interface{}{"order": 2.0}, want: 2},
+		{name: "order string", config: map[string]interface{}{"order": "-1"}, want: -1},
+		{name: "priority", config: map[string]interface{}{"priority": 10.0}, want: -10},
+		{name: "order wins", config: map[string]interface{}{"order": 1.0, "priority": 10.0}, want: 1},
+		{name: "invalid order", config: map[string]interface{}{"order": "first"}, wantErr: "invalid order"},
+		{name: "fractional order", config: map[string]interface{}{"order": 1.5}, wantErr: "invalid order"},
+		{name: "invalid priority", config: map[string]interface{}{"priority": true}, wantErr: "invalid priority"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NodeOrder(test.config)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got %d, %v, want an error containing %q", got, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("got %d, %v, want %d", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestTopologicalOrderRanks(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs map[string]map[string]interface{}
+		edges   [][2]string
+		want    string
+	}{
+		{
+			name: "declaration order",
+			want: "a,b,c,d",
+		},
+		{
+			name:    "order",
+			configs: map[string]map[string]interface{}{"d": {"order": -1.0}, "a": {"order": 1.0}},
+			want:    "d,b,c,a",
+		},
+		{
+			name:    "priority",
+			configs: map[string]map[string]interface{}{"c": {"priority": 5.0}, "b": {"priority": 1.0}},
+			want:    "c,b,a,d",
+		},
+		{
+			name:    "dependencies first",
+			configs: map[string]map[string]interface{}{"a": {"order": -1.0}},
+			edges:   [][2]string{{"d", "a"}},
+			want:    "b,c,d,a",
+		},
+		{
+			name:    "invalid order ranked as default",
+			configs: map[string]map[string]interface{}{"a": {"order": "first"}, "c": {"order": -1.0}},
+			want:    "c,a,b,d",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes := make([]*funcNode, 0)
+			for i, id := range []string{"a", "b", "c", "d"} {
+				node := newFuncNode(id, nil)
+				// Later nodes sit higher on the canvas, which must not matter
+				node.SetPosition(types.Position{X: 0, Y: float32(100 - 10*i)})
+				for key, value := range test.configs[id] {
+					node.SetConfigValue(key, value)
+				}
+				nodes = append(nodes, node)
+			}
+
+			order, err := newTestGraph(nodes, test.edges...).GetTopologicalOrder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(order, ","); got != test.want {
+				t.Errorf("order = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInvalidOrderFailsNode(t *testing.T) {
+	node := newFuncNode("a", nil)
+	node.SetConfigValue("priority", "high")
+
+	results, err := NewExecutor(newTestGraph([]*funcNode{node})).ExecuteGraph(context.Background())
+	if err == nil {
+		t.Fatal("run with an invalid priority succeeded")
+	}
+	if len(results) != 1 || results[0].Status != types.StatusFailed || !strings.Contains(results[0].Error, "invalid priority") {
+		t.Errorf("results = %+v, want a failed node reporting the invalid priority", results)
+	}
+}
+
+func TestValidateNodeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{name: "empty", config: map[string]interface{}{}},
+		{name: "valid", config: map[string]interface{}{"timeout": "5s", "order": 1.0, "retry": map[string]interface{}{"max_attempts": 2.0}}},
+		{name: "timeout", config: map[string]interface{}{"timeout": "soon"}, wantErr: "invalid timeout"},
+		{name: "retry", config: map[string]interface{}{"retry": map[string]interface{}{"backoff": "linear"}}, wantErr: "invalid retry policy"},
+		{name: "order", config: map[string]interface{}{"order": "first"}, wantErr: "invalid order"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateNodeConfig(test.config)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
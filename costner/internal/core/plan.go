@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"costner/pkg/types"
+)
+
+// SecretMask replaces secret values in execution plans and traces.
+const SecretMask = "********"
+
+// secretNameParts mark input names, map keys and variable names whose
+// values are treated as secrets.
+var secretNameParts = []string{
+	"secret", "password", "passwd", "token", "authorization",
+	"api_key", "apikey", "credential", "cookie", "private_key",
+}
+
+// IsSecretName reports whether values of an input, map key, header or
+// variable with this name are treated as secrets.
+func IsSecretName(name string) bool {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecrets replaces the value of name, and of any nested map entries,
+// with SecretMask when the name looks like a secret.
+func MaskSecrets(name string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if IsSecretName(name) {
+		return SecretMask
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = MaskSecrets(key, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = MaskSecrets("", item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// undefinedVariables adds the names of placeholders in value that have no
+// variable to found.
+func undefinedVariables(value interface{}, variables map[string]interface{}, found map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		for _, match := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+			if _, exists := variables[match[1]]; !exists {
+				found[match[1]] = true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			undefinedVariables(item, variables, found)
+		}
+	case []interface{}:
+		for _, item := range v {
+			undefinedVariables(item, variables, found)
+		}
+	}
+}
+
+// Plan describes what executing the graph would do without running any
+// node: the execution order, the resolved static inputs with secrets
+// masked, the wired inputs and the required inputs that have no value.
+// With a target, only the target and its upstream nodes are planned.
+func (e *Executor) Plan(target string) (*types.ExecutionPlan, error) {
+	graph, err := e.graph.snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution order: %w", err)
+	}
+	order := graph.order
+
+	if target != "" {
+		if _, exists := e.graph.GetNode(target); !exists {
+			return nil, fmt.Errorf("%w: %s", types.ErrNodeNotFound, target)
+		}
+		subset := map[string]bool{target: true}
+		for _, ancestor := range e.graph.GetAncestors(target) {
+			subset[ancestor] = true
+		}
+		filtered := make([]string, 0, len(subset))
+		for _, nodeID := range order {
+			if subset[nodeID] {
+				filtered = append(filtered, nodeID)
+			}
+		}
+		order = filtered
+	}
+
+	// Substitute secret variables with the mask so they never show up,
+	// not even inside larger strings
+	variables := e.Variables()
+	masked := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		if IsSecretName(name) {
+			masked[name] = SecretMask
+		} else {
+			masked[name] = value
+		}
+	}
+
+	plan := &types.ExecutionPlan{Steps: make([]types.PlanStep, 0, len(order))}
+	for _, nodeID := range order {
+		node := graph.nodes[nodeID]
+
+		step := types.PlanStep{
+			NodeID:      nodeID,
+			NodeType:    node.Type(),
+			Name:        node.Name(),
+			SideEffects: types.HasSideEffects(node),
+			Inputs:      make(map[string]interface{}),
+		}
+
+		wired := make(map[string]bool)
+		for _, conn := range graph.connections {
+			if conn.TargetNode == nodeID {
+				step.Wired = append(step.Wired, types.WiredInput{
+					Input:      conn.TargetPort,
+					SourceNode: conn.SourceNode,
+					SourcePort: conn.SourcePort,
+				})
+				wired[conn.TargetPort] = true
+			}
+		}
+
+		undefined := make(map[string]bool)
+		for _, input := range graph.inputs[nodeID] {
+			if wired[input.Name] {
+				continue
+			}
+			if input.Value == nil {
+				if input.Required {
+					step.Unresolved = append(step.Unresolved, input.Name)
+				}
+				continue
+			}
+			undefinedVariables(input.Value, variables, undefined)
+			step.Inputs[input.Name] = MaskSecrets(input.Name, SubstituteVariables(input.Value, masked))
+		}
+		for name := range undefined {
+			step.UndefinedVariables = append(step.UndefinedVariables, name)
+		}
+		sort.Strings(step.UndefinedVariables)
+
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+func TestIsSecretName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"password":      true,
+		"DB_PASSWORD":   true,
+		"api_token":     true,
+		"X-Api-Key":     true,
+		"Authorization": true,
+		"Set-Cookie":    true,
+		"client_secret": true,
+		"user":          false,
+		"url":           false,
+		"keys":          false,
+	} {
+		if got := IsSecretName(name); got != want {
+			t.Errorf("IsSecretName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestPlanMasksSecrets(t *testing.T) {
+	node := newFuncNode("login", nil)
+	node.Inputs = []types.NodeInput{
+		{Name: "url", Type: types.PortString, Value: "https://api/{{user}}?key={{api_key}}"},
+		{Name: "password", Type: types.PortString, Value: "literal"},
+		{Name: "headers", Type: types.PortMap, Value: map[string]interface{}{"Authorization": "Bearer {{api_key}}", "Accept": "json"}},
+		{Name: "body", Type: types.PortString, Value: "{{missing}}"},
+		{Name: "required", Type: types.PortString, Required: true},
+	}
+	executor := NewExecutor(newTestGraph([]*funcNode{node}))
+	executor.SetVariables(map[string]interface{}{"user": "alice", "api_key": "k-123"})
+
+	plan, err := executor.Plan("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := plan.Steps[0]
+
+	want := map[string]interface{}{
+		"url":      "https://api/alice?key=" + SecretMask,
+		"password": SecretMask,
+		"headers":  map[string]interface{}{"Authorization": SecretMask, "Accept": "json"},
+		"body":     "{{missing}}",
+	}
+	if !reflect.DeepEqual(step.Inputs, want) {
+		t.Errorf("inputs %v\nwant %v", step.Inputs, want)
+	}
+	if !reflect.DeepEqual(step.UndefinedVariables, []string{"missing"}) {
+		t.Errorf("undefined variables %v, want missing", step.UndefinedVariables)
+	}
+	if !reflect.DeepEqual(step.Unresolved, []string{"required"}) || plan.Unresolved() != 1 {
+		t.Errorf("unresolved %v, want required", step.Unresolved)
+	}
+
+	// Planning leaves the variables themselves alone
+	if executor.Variables()["api_key"] != "k-123" {
+		t.Error("Plan changed the executor variables")
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"costner/pkg/types"
+)
+
+const (
+	BackoffFixed       = "fixed"
+	BackoffExponential = "exponential"
+)
+
+// RetryPolicy describes how the executor retries a node. It is read from the
+// "retry" key of the node config, for example:
+//
+//	"retry": {"max_attempts": 4, "backoff": "exponential", "delay": "500ms",
+//	          "max_delay": "10s", "jitter": true, "retry_on_status": [502, 503, 429]}
+type RetryPolicy struct {
+	MaxAttempts       int
+	Backoff           string
+	Delay             time.Duration
+	MaxDelay          time.Duration
+	Jitter            bool
+	RetryOnStatus     []int
+	RetryOnError      bool
+	RespectRetryAfter bool
+}
+
+// RetryPolicyFromConfig parses the retry policy of a node. It returns nil
+// when the node has no retry configuration.
+func RetryPolicyFromConfig(config map[string]interface{}) (*RetryPolicy, error) {
+	raw, exists := config["retry"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	settings, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("retry config must be an object, got %T", raw)
+	}
+
+	policy := &RetryPolicy{
+		MaxAttempts:       3,
+		Backoff:           BackoffExponential,
+		Delay:             time.Second,
+		MaxDelay:          30 * time.Second,
+		RetryOnError:      true,
+		RespectRetryAfter: true,
+	}
+
+	var err error
+	if v, exists := settings["max_attempts"]; exists {
+		if policy.MaxAttempts, err = parseInt(v); err != nil {
+			return nil, fmt.Errorf("retry max_attempts: %w", err)
+		}
+	}
+	if v, ok := settings["backoff"].(string); ok && v != "" {
+		if v != BackoffFixed && v != BackoffExponential {
+			return nil, fmt.Errorf("unknown retry backoff: %s", v)
+		}
+		policy.Backoff = v
+	}
+	if v, exists := settings["delay"]; exists {
+		if policy.Delay, err = parseDuration(v); err != nil {
+			return nil, fmt.Errorf("retry delay: %w", err)
+		}
+	}
+	if v, exists := settings["max_delay"]; exists {
+		if policy.MaxDelay, err = parseDuration(v); err != nil {
+			return nil, fmt.Errorf("retry max_delay: %w", err)
+		}
+	}
+	if v, ok := settings["jitter"].(bool); ok {
+		policy.Jitter = v
+	}
+	if v, ok := settings["retry_on_error"].(bool); ok {
+		policy.RetryOnError = v
+	}
+	if v, ok := settings["respect_retry_after"].(bool); ok {
+		policy.RespectRetryAfter = v
+	}
+	if v, exists := settings["retry_on_status"]; exists {
+		codes, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("retry retry_on_status must be a list, got %T", v)
+		}
+		for _, code := range codes {
+			status, err := parseInt(code)
+			if err != nil {
+				return nil, fmt.Errorf("retry retry_on_status: %w", err)
+			}
+			policy.RetryOnStatus = append(policy.RetryOnStatus, status)
+		}
+	}
+
+	if policy.MaxAttempts < 1 {
+		return nil, fmt.Errorf("retry max_attempts must be at least 1")
+	}
+	return policy, nil
+}
+
+// shouldRetry reports whether an attempt failed in a way the policy retries.
+func (p *RetryPolicy) shouldRetry(outputs map[string]interface{}, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return p.RetryOnError
+	}
+
+	status, ok := outputs["status_code"].(int)
+	if !ok {
+		return false
+	}
+	for _, code := range p.RetryOnStatus {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given failed attempt.
+func (p *RetryPolicy) backoff(attempt int, outputs map[string]interface{}) time.Duration {
+	if p.RespectRetryAfter {
+		if delay, ok := retryAfter(outputs); ok {
+			return p.capDelay(delay)
+		}
+	}
+
+	delay := p.Delay
+	if p.Backoff == BackoffExponential {
+		// Stop doubling once the cap is reached, or before overflowing when
+		// there is no cap
+		for i := 1; i < attempt; i++ {
+			if (p.MaxDelay > 0 && delay >= p.MaxDelay) || delay > math.MaxInt64/2 {
+				break
+			}
+			delay *= 2
+		}
+	}
+	delay = p.capDelay(delay)
+
+	// Equal jitter: keep half the delay and randomise the rest
+	if p.Jitter && delay > 0 {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+	return delay
+}
+
+func (p *RetryPolicy) capDelay(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// retryAfter reads a Retry-After response header given either in seconds or
+// as an HTTP date.
+func retryAfter(outputs map[string]interface{}) (time.Duration, bool) {
+	headers, ok := outputs["headers"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	value, ok := headers["Retry-After"].(string)
+	if !ok || value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// executeWithRetry runs a node according to its retry policy and records
+// every attempt on the result. Nodes without a policy run exactly once.
+func (e *Executor) executeWithRetry(ctx context.Context, node types.Node, inputs map[string]interface{}, result *types.ExecutionResult) (map[string]interface{}, error) {
+	policy, err := RetryPolicyFromConfig(node.GetConfig())
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %w", err)
+	}
+	if policy == nil {
+		return invoke(ctx, node, inputs)
+	}
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		outputs, err := invoke(ctx, node, inputs)
+
+		record := types.AttemptResult{
+			Attempt:   attempt,
+			Success:   err == nil,
+			Duration:  time.Since(start),
+			Timestamp: start,
+		}
+		if status, ok := outputs["status_code"].(int); ok {
+			record.StatusCode = status
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+
+		if attempt >= policy.MaxAttempts || !policy.shouldRetry(outputs, err) {
+			result.Attempts = append(result.Attempts, record)
+			return outputs, err
+		}
+
+		record.Success = false
+		if err == nil {
+			record.Error = fmt.Sprintf("retryable status code %d", record.StatusCode)
+		}
+		record.Delay = policy.backoff(attempt, outputs)
+		result.Attempts = append(result.Attempts, record)
+
+		timer := time.NewTimer(record.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
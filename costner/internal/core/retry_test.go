@@ -0,0 +1,192 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		outputs map[string]interface{}
+		want    time.Duration
+	}{
+		{
+			name:    "fixed",
+			policy:  RetryPolicy{Backoff: BackoffFixed, Delay: time.Second, MaxDelay: 30 * time.Second},
+			attempt: 4,
+			want:    time.Second,
+		},
+		{
+			name:    "exponential first attempt",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 30 * time.Second},
+			attempt: 1,
+			want:    time.Second,
+		},
+		{
+			name:    "exponential",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 30 * time.Second},
+			attempt: 4,
+			want:    8 * time.Second,
+		},
+		{
+			name:    "exponential capped",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second},
+			attempt: 4,
+			want:    5 * time.Second,
+		},
+		{
+			name:    "exponential without cap",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second},
+			attempt: 4,
+			want:    8 * time.Second,
+		},
+		{
+			name:    "exponential without cap does not overflow",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second},
+			attempt: 100,
+			want:    time.Second << 33,
+		},
+		{
+			name:    "retry after",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 30 * time.Second, RespectRetryAfter: true},
+			attempt: 1,
+			outputs: map[string]interface{}{"headers": map[string]interface{}{"Retry-After": "7"}},
+			want:    7 * time.Second,
+		},
+		{
+			name:    "retry after capped",
+			policy:  RetryPolicy{Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second, RespectRetryAfter: true},
+			attempt: 1,
+			outputs: map[string]interface{}{"headers": map[string]interface{}{"Retry-After": "7"}},
+			want:    5 * time.Second,
+		},
+		{
+			name:    "retry after ignored",
+			policy:  RetryPolicy{Backoff: BackoffFixed, Delay: time.Second},
+			attempt: 1,
+			outputs: map[string]interface{}{"headers": map[string]interface{}{"Retry-After": "7"}},
+			want:    time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.backoff(test.attempt, test.outputs); got != test.want {
+				t.Errorf("backoff(%d) = %v, want %v", test.attempt, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{Backoff: BackoffFixed, Delay: time.Second, Jitter: true}
+	for i := 0; i < 100; i++ {
+		if delay := policy.backoff(1, nil); delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("jittered delay %v is outside [500ms, 1s]", delay)
+		}
+	}
+}
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    *RetryPolicy
+		wantErr bool
+	}{
+		{name: "none", config: map[string]interface{}{}},
+		{
+			name:   "defaults",
+			config: map[string]interface{}{"retry": map[string]interface{}{}},
+			want:   &RetryPolicy{MaxAttempts: 3, Backoff: BackoffExponential, Delay: time.Second, MaxDelay: 30 * time.Second, RetryOnError: true, RespectRetryAfter: true},
+		},
+		{
+			name: "explicit",
+			config: map[string]interface{}{"retry": map[string]interface{}{
+				"max_attempts": 4.0, "backoff": "fixed", "delay": "500ms", "max_delay": 0.0,
+				"jitter": true, "retry_on_status": []interface{}{502.0, 503.0}, "retry_on_error": false, "respect_retry_after": false,
+			}},
+			want: &RetryPolicy{MaxAttempts: 4, Backoff: BackoffFixed, Delay: 500 * time.Millisecond, Jitter: true, RetryOnStatus: []int{502, 503}},
+		},
+		{name: "not an object", config: map[string]interface{}{"retry": true}, wantErr: true},
+		{name: "unknown backoff", config: map[string]interface{}{"retry": map[string]interface{}{"backoff": "linear"}}, wantErr: true},
+		{name: "no attempts", config: map[string]interface{}{"retry": map[string]interface{}{"max_attempts": 0.0}}, wantErr: true},
+		{name: "bad status list", config: map[string]interface{}{"retry": map[string]interface{}{"retry_on_status": 503.0}}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RetryPolicyFromConfig(test.config)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got policy %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (test.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, test.want)
+			}
+			if got == nil {
+				return
+			}
+			if got.MaxAttempts != test.want.MaxAttempts || got.Backoff != test.want.Backoff ||
+				got.Delay != test.want.Delay || got.MaxDelay != test.want.MaxDelay ||
+				got.Jitter != test.want.Jitter || got.RetryOnError != test.want.RetryOnError ||
+				got.RespectRetryAfter != test.want.RespectRetryAfter ||
+				len(got.RetryOnStatus) != len(test.want.RetryOnStatus) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        map[string]interface{}
+		failures     int
+		status       int
+		wantAttempts int
+		wantSuccess  bool
+	}{
+		{name: "succeeds after errors", retry: map[string]interface{}{"max_attempts": 3.0, "delay": "1ms"}, failures: 2, wantAttempts: 3, wantSuccess: true},
+		{name: "gives up", retry: map[string]interface{}{"max_attempts": 2.0, "delay": "1ms"}, failures: 5, wantAttempts: 2},
+		{name: "errors not retried", retry: map[string]interface{}{"max_attempts": 3.0, "delay": "1ms", "retry_on_error": false}, failures: 5, wantAttempts: 1},
+		{name: "retryable status", retry: map[string]interface{}{"max_attempts": 3.0, "delay": "1ms", "retry_on_status": []interface{}{503.0}}, status: 503, wantAttempts: 3, wantSuccess: true},
+		{name: "other status", retry: map[string]interface{}{"max_attempts": 3.0, "delay": "1ms", "retry_on_status": []interface{}{503.0}}, status: 500, wantAttempts: 1, wantSuccess: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			node := newFuncNode("n", func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+				calls++
+				if calls <= test.failures {
+					return nil, errors.New("connection refused")
+				}
+				return map[string]interface{}{"status_code": test.status}, nil
+			})
+			node.SetConfigValue("retry", test.retry)
+
+			results, _ := NewExecutor(newTestGraph([]*funcNode{node})).ExecuteGraph(context.Background())
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			result := results[0]
+			if len(result.Attempts) != test.wantAttempts || calls != test.wantAttempts {
+				t.Errorf("recorded %d attempts after %d calls, want %d", len(result.Attempts), calls, test.wantAttempts)
+			}
+			if result.Success != test.wantSuccess {
+				t.Errorf("success = %v, want %v", result.Success, test.wantSuccess)
+			}
+		})
+	}
+}
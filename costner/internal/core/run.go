@@ -0,0 +1,123 @@
+package core
+
+import (
+	"sync"
+
+	"costner/pkg/types"
+)
+
+// snapshot is a copy of the graph taken when a run starts. Nodes are cloned
+// and their inputs copied, so edits made while a run is in progress only
+// affect later runs, and concurrent runs never touch the graph's nodes.
+type snapshot struct {
+	nodes       map[string]types.Node
+	inputs      map[string][]types.NodeInput
+	revisions   map[string]uint64
+	connections []types.Connection
+	order       []string
+}
+
+func (g *Graph) snapshot() (*snapshot, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{
+		nodes:       make(map[string]types.Node, len(g.nodes)),
+		inputs:      make(map[string][]types.NodeInput, len(g.nodes)),
+		revisions:   make(map[string]uint64, len(g.nodes)),
+		connections: make([]types.Connection, len(g.connections)),
+		order:       order,
+	}
+	for id, node := range g.nodes {
+		inputs := node.GetInputs()
+		copied := make([]types.NodeInput, len(inputs))
+		copy(copied, inputs)
+
+		s.nodes[id] = node.Clone()
+		s.inputs[id] = copied
+		s.revisions[id] = g.revisions[id]
+	}
+	copy(s.connections, g.connections)
+	return s, nil
+}
+
+// runState holds everything produced by one execution. Every run gets its
+// own state, so several runs over one graph can proceed at the same time.
+type runState struct {
+	graph          *snapshot
+	variables      map[string]interface{}
+	maxConcurrency int
+	keepGoing      bool
+
+	results map[string]map[string]interface{}
+	skipped map[string]skipInfo
+	failed  map[string]bool
+	mutex   sync.RWMutex
+}
+
+// newRun snapshots the graph and the executor settings for a fresh run.
+func (e *Executor) newRun() (*runState, error) {
+	graph, err := e.graph.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return &runState{
+		graph:          graph,
+		variables:      e.variables,
+		maxConcurrency: e.maxConcurrency,
+		keepGoing:      e.keepGoing,
+		results:        make(map[string]map[string]interface{}),
+		skipped:        make(map[string]skipInfo),
+		failed:         make(map[string]bool),
+	}, nil
+}
+
+// seed copies the outcome of a previous run, so that a single node can be
+// executed against the outputs of its upstream nodes.
+func (r *runState) seed(previous *runState) {
+	previous.mutex.RLock()
+	defer previous.mutex.RUnlock()
+
+	for id, outputs := range previous.results {
+		r.results[id] = outputs
+	}
+	for id, skip := range previous.skipped {
+		r.skipped[id] = skip
+	}
+	for id := range previous.failed {
+		r.failed[id] = true
+	}
+}
+
+func (r *runState) result(nodeID string) (map[string]interface{}, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	outputs, exists := r.results[nodeID]
+	return outputs, exists
+}
+
+func (r *runState) setResult(nodeID string, outputs map[string]interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.results[nodeID] = outputs
+}
+
+func (r *runState) markSkipped(nodeID string, skip skipInfo) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.skipped[nodeID] = skip
+}
+
+func (r *runState) markFailed(nodeID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failed[nodeID] = true
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// echo outputs its "in" input, so that a run shows which input values it
+// saw.
+func echo(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"out": inputs["in"]}, nil
+}
+
+// TestConcurrentRunsWithEdits runs one graph from several executors while
+// the graph is being edited; run it with -race. Every run must see a
+// consistent snapshot, so the value a passes on arrives unchanged at b.
+func TestConcurrentRunsWithEdits(t *testing.T) {
+	nodes := []*funcNode{newFuncNode("a", echo), newFuncNode("b", echo)}
+	graph := newTestGraph(nodes, [2]string{"a", "b"})
+	if err := graph.SetInputValue("a", "in", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var edits sync.WaitGroup
+	edits.Add(1)
+	go func() {
+		defer edits.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			id := fmt.Sprintf("extra%d", i)
+			graph.SetInputValue("a", "in", i)
+			graph.AddNode(newFuncNode(id, echo))
+			graph.AddConnection(types.Connection{ID: id, SourceNode: "a", SourcePort: "out", TargetNode: id, TargetPort: "in"})
+			graph.MoveNode("b", types.Position{X: float32(i)})
+			graph.RemoveNode(id)
+		}
+	}()
+
+	var runs sync.WaitGroup
+	shared := NewExecutor(graph)
+	for i := 0; i < 4; i++ {
+		runs.Add(1)
+		executor := shared
+		if i%2 == 1 {
+			executor = NewExecutor(graph)
+		}
+		go func() {
+			defer runs.Done()
+			for j := 0; j < 50; j++ {
+				results, err := executor.ExecuteGraph(context.Background())
+				if err != nil {
+					t.Errorf("run failed: %v", err)
+					return
+				}
+				outputs := make(map[string]interface{})
+				for _, result := range results {
+					outputs[result.NodeID] = result.Outputs["out"]
+				}
+				if outputs["a"] != outputs["b"] {
+					t.Errorf("a passed on %v but b received %v", outputs["a"], outputs["b"])
+					return
+				}
+			}
+		}()
+	}
+	runs.Wait()
+	close(done)
+	edits.Wait()
+
+}
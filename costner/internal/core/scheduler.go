@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"costner/pkg/types"
+)
+
+type nodeOutcome struct {
+	nodeID string
+	result types.ExecutionResult
+	err    error
+}
+
+// schedule executes the given nodes, which must be in topological order,
+// starting every node as soon as all of its upstream nodes have finished.
+// At most maxConcurrency nodes run at once. Results are returned in the
+// order of the input slice regardless of completion order.
+//
+// By default no new nodes are started once one fails, and the nodes that
+// never started are reported as skipped. In keep-going mode the remaining
+// nodes still run and the dependents of failed nodes are skipped.
+func (e *Executor) schedule(ctx context.Context, run *runState, order []string) ([]types.ExecutionResult, error) {
+	position := make(map[string]int, len(order))
+	for i, nodeID := range order {
+		position[nodeID] = i
+	}
+
+	// Count distinct upstream nodes and record dependents within the set
+	pending := make(map[string]int, len(order))
+	dependents := make(map[string][]string)
+	seen := make(map[[2]string]bool)
+	for _, conn := range run.graph.connections {
+		if _, ok := position[conn.SourceNode]; !ok {
+			continue
+		}
+		if _, ok := position[conn.TargetNode]; !ok {
+			continue
+		}
+		edge := [2]string{conn.SourceNode, conn.TargetNode}
+		if seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		pending[conn.TargetNode]++
+		dependents[conn.SourceNode] = append(dependents[conn.SourceNode], conn.TargetNode)
+	}
+
+	ready := make([]string, 0)
+	for _, nodeID := range order {
+		if pending[nodeID] == 0 {
+			ready = append(ready, nodeID)
+		}
+	}
+
+	results := make([]*types.ExecutionResult, len(order))
+	done := make(chan nodeOutcome)
+	running := 0
+	failures := 0
+	stoppedAt := ""
+	var firstErr error
+
+	for {
+		// Nothing new is started once the run's context is done
+		if ctx.Err() != nil && firstErr == nil {
+			firstErr = fmt.Errorf("execution aborted: %w", ctx.Err())
+		}
+
+		// Launch ready nodes while there is capacity and nothing has failed
+		for ctx.Err() == nil && (firstErr == nil || run.keepGoing) && len(ready) > 0 && running < run.maxConcurrency {
+			nodeID := ready[0]
+			ready = ready[1:]
+
+			if _, exists := run.graph.nodes[nodeID]; !exists {
+				firstErr = fmt.Errorf("node not found during execution: %s", nodeID)
+				break
+			}
+
+			running++
+			go func(nodeID string) {
+				result, err := e.executeNode(ctx, run, nodeID)
+				done <- nodeOutcome{nodeID: nodeID, result: result, err: err}
+			}(nodeID)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		outcome := <-done
+		running--
+		result := outcome.result
+		results[position[outcome.nodeID]] = &result
+
+		if outcome.err != nil {
+			// Nodes interrupted by the end of the run are not failures
+			if ctx.Err() != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("execution aborted: %w", ctx.Err())
+				}
+				continue
+			}
+			failures++
+			if run.keepGoing {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("node %s: %w", outcome.nodeID, outcome.err)
+				}
+			} else {
+				// Stop launching new nodes, but let running ones finish
+				if firstErr == nil {
+					firstErr = fmt.Errorf("execution stopped at node %s: %w", outcome.nodeID, outcome.err)
+					stoppedAt = outcome.nodeID
+				}
+				continue
+			}
+		}
+
+		for _, dependent := range dependents[outcome.nodeID] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		sort.SliceStable(ready, func(i, j int) bool {
+			return position[ready[i]] < position[ready[j]]
+		})
+	}
+
+	// Report the nodes an aborted or stopped run never got to as skipped
+	reason := ""
+	if ctx.Err() != nil {
+		reason = "run cancelled"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			reason = "graph deadline exceeded"
+		}
+	} else if stoppedAt != "" {
+		reason = fmt.Sprintf("run stopped after %s failed", stoppedAt)
+	}
+	if reason != "" {
+		for i, nodeID := range order {
+			if results[i] != nil {
+				continue
+			}
+			run.markSkipped(nodeID, skipInfo{reason: reason})
+			result := types.ExecutionResult{
+				NodeID:     nodeID,
+				Status:     types.StatusSkipped,
+				SkipReason: reason,
+				Timestamp:  time.Now(),
+			}
+			e.emitResult(result)
+			results[i] = &result
+		}
+	}
+
+	ordered := make([]types.ExecutionResult, 0, len(order))
+	for _, result := range results {
+		if result != nil {
+			ordered = append(ordered, *result)
+		}
+	}
+
+	if run.keepGoing && failures > 0 {
+		return ordered, fmt.Errorf("%d node(s) failed, first error: %w", failures, firstErr)
+	}
+
+	return ordered, firstErr
+}
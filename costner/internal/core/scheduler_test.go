@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"costner/pkg/types"
+)
+
+func failing(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	return nil, errors.New("boom")
+}
+
+// statuses formats results as "id:status" pairs, with the skip reason in
+// parentheses for skipped nodes.
+func statuses(results []types.ExecutionResult) string {
+	parts := make([]string, len(results))
+	for i, result := range results {
+		parts[i] = result.NodeID + ":" + string(result.Status)
+		if result.SkipReason != "" {
+			parts[i] += "(" + result.SkipReason + ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestScheduleFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepGoing bool
+		want      string
+	}{
+		{
+			name: "stop",
+			want: "a:failed b:skipped(run stopped after a failed) c:skipped(run stopped after a failed) d:skipped(run stopped after a failed)",
+		},
+		{
+			name:      "keep going",
+			keepGoing: true,
+			want:      "a:failed b:skipped(upstream node a failed) c:skipped(upstream node a failed) d:success",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a -> b -> c, with d independent of all of them
+			nodes := []*funcNode{newFuncNode("a", failing), newFuncNode("b", nil), newFuncNode("c", nil), newFuncNode("d", nil)}
+			executor := NewExecutor(newTestGraph(nodes, [2]string{"a", "b"}, [2]string{"b", "c"}))
+			executor.SetMaxConcurrency(1)
+			executor.SetKeepGoing(test.keepGoing)
+
+			results, err := executor.ExecuteGraph(context.Background())
+			if err == nil {
+				t.Fatal("run with a failing node succeeded")
+			}
+			if got := statuses(results); got != test.want {
+				t.Errorf("results = %s\nwant      %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScheduleConcurrency(t *testing.T) {
+	var running, peak int32
+	slow := func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		now := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return map[string]interface{}{"out": 1}, nil
+	}
+
+	nodes := make([]*funcNode, 0)
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		nodes = append(nodes, newFuncNode(id, slow))
+	}
+	executor := NewExecutor(newTestGraph(nodes))
+	executor.SetMaxConcurrency(3)
+
+	results, err := executor.ExecuteGraph(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak != 3 {
+		t.Errorf("peak concurrency = %d, want 3", peak)
+	}
+	if got := statuses(results); got != "a:success b:success c:success d:success e:success f:success" {
+		t.Errorf("results = %s, want all in declaration order", got)
+	}
+}
+
+func TestScheduleDependencies(t *testing.T) {
+	var mutex sync.Mutex
+	finished := make(map[string]bool)
+	check := func(id string, upstream ...string) func(context.Context, map[string]interface{}) (map[string]interface{}, error) {
+		return func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, dependency := range upstream {
+				if !finished[dependency] {
+					return nil, errors.New(id + " started before " + dependency)
+				}
+			}
+			finished[id] = true
+			return map[string]interface{}{"out": id}, nil
+		}
+	}
+
+	// Diamond: a -> b, a -> c, b -> d, c -> d; d collects both in a list
+	nodes := []*funcNode{
+		newFuncNode("a", check("a")),
+		newFuncNode("b", check("b", "a")),
+		newFuncNode("c", check("c", "a")),
+		newFuncNode("d", check("d", "b", "c")),
+	}
+	nodes[3].Inputs = []types.NodeInput{{Name: "in", Type: "list"}}
+	graph := newTestGraph(nodes, [2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "d"}, [2]string{"c", "d"})
+	if len(graph.GetConnections()) != 4 {
+		t.Fatalf("got %d connections, want 4", len(graph.GetConnections()))
+	}
+
+	results, err := NewExecutor(graph).ExecuteGraph(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(results); got != "a:success b:success c:success d:success" {
+		t.Errorf("results = %s", got)
+	}
+}
+
+func TestScheduleCancellation(t *testing.T) {
+	started := make(chan struct{})
+	blocking := func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	nodes := []*funcNode{newFuncNode("a", blocking), newFuncNode("b", nil), newFuncNode("c", nil)}
+	executor := NewExecutor(newTestGraph(nodes, [2]string{"a", "b"}))
+	executor.SetMaxConcurrency(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	results, err := executor.ExecuteGraph(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want it to wrap context.Canceled", err)
+	}
+	want := "a:cancelled b:skipped(run cancelled) c:skipped(run cancelled)"
+	if got := statuses(results); got != want {
+		t.Errorf("results = %s\nwant      %s", got, want)
+	}
+}
+
+func TestCancellationKeepsPartialResults(t *testing.T) {
+	started := make(chan struct{})
+	blocking := func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	// done finishes before slow blocks; after depends on slow
+	nodes := []*funcNode{newFuncNode("done", nil), newFuncNode("slow", blocking), newFuncNode("after", nil)}
+	executor := NewExecutor(newTestGraph(nodes, [2]string{"slow", "after"}))
+	executor.SetMaxConcurrency(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	results, err := executor.ExecuteGraph(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want it to wrap context.Canceled", err)
+	}
+	want := "done:success slow:cancelled after:skipped(run cancelled)"
+	if got := statuses(results); got != want {
+		t.Fatalf("results = %s\nwant      %s", got, want)
+	}
+	if results[0].Outputs["out"] != "done" {
+		t.Errorf("outputs of the completed node = %v", results[0].Outputs)
+	}
+
+	if outputs, ok := executor.GetNodeResult("done"); !ok || outputs["out"] != "done" {
+		t.Errorf("GetNodeResult(done) = %v, %v; want the outputs of the cancelled run", outputs, ok)
+	}
+	for _, id := range []string{"slow", "after"} {
+		if outputs, ok := executor.GetNodeResult(id); ok {
+			t.Errorf("GetNodeResult(%s) = %v, want no result", id, outputs)
+		}
+	}
+}
+
+func TestKeepGoingReportsSkippedDependents(t *testing.T) {
+	// Diamond a -> b, a -> c, b -> d, c -> d where a fails; e -> f runs
+	// independently, and g fails on its own
+	nodes := []*funcNode{
+		newFuncNode("a", failing), newFuncNode("b", nil), newFuncNode("c", nil), newFuncNode("d", nil),
+		newFuncNode("e", nil), newFuncNode("f", nil), newFuncNode("g", failing),
+	}
+	nodes[3].Inputs = []types.NodeInput{{Name: "in", Type: "list"}}
+	graph := newTestGraph(nodes, [2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "d"}, [2]string{"c", "d"}, [2]string{"e", "f"})
+	executor := NewExecutor(graph)
+	executor.SetMaxConcurrency(1)
+	executor.SetKeepGoing(true)
+
+	var skipped []string
+	unsubscribe := executor.Observe(func(event types.ExecutionEvent) {
+		if event.Type == types.EventNodeSkipped {
+			skipped = append(skipped, describe(event))
+		}
+	})
+	results, err := executor.ExecuteGraph(context.Background())
+	unsubscribe()
+
+	if err == nil || err.Error() != "2 node(s) failed, first error: node a: boom" {
+		t.Errorf("error = %v", err)
+	}
+	want := "a:failed b:skipped(upstream node a failed) c:skipped(upstream node a failed) d:skipped(upstream node a failed) e:success f:success g:failed"
+	if got := statuses(results); got != want {
+		t.Errorf("results = %s\nwant      %s", got, want)
+	}
+	if results[5].Outputs["out"] != "f" {
+		t.Errorf("outputs of f = %v", results[5].Outputs)
+	}
+
+	wantSkipped := []string{
+		"node_skipped b upstream node a failed",
+		"node_skipped c upstream node a failed",
+		"node_skipped d upstream node a failed",
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skip events = %q\nwant         %q", skipped, wantSkipped)
+	}
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+
+	"costner/pkg/types"
+)
+
+// RunSubgraph executes a nested graph owned by a container node and returns
+// its results together with the outputs of every node that succeeded. The
+// nested run shares the caller's context, so node timeouts, the graph
+// deadline and cancellation of the outer run all apply to it. It also
+// inherits the variables and the keep-going setting of the run executing
+// the container. Results are never cached between nested runs.
+func RunSubgraph(ctx context.Context, graph *Graph) ([]types.ExecutionResult, map[string]map[string]interface{}, error) {
+	executor := NewExecutor(graph)
+	executor.SetMaxConcurrency(1)
+	if parent, ok := ctx.Value(runKey).(*runState); ok {
+		executor.SetVariables(parent.variables)
+		executor.SetKeepGoing(parent.keepGoing)
+	}
+
+	results, err := executor.ExecuteGraph(ctx)
+
+	outputs := make(map[string]map[string]interface{})
+	for _, result := range results {
+		if result.Success {
+			outputs[result.NodeID] = result.Outputs
+		}
+	}
+	return results, outputs, err
+}
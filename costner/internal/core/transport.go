@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"net/http"
+
+	"costner/internal/httppool"
+	"costner/pkg/types"
+)
+
+type contextKey int
+
+const (
+	transportKey contextKey = iota
+	nodeIDKey
+	runKey
+)
+
+// WithTransport returns a context under which request nodes send their
+// HTTP requests through transport instead of the default one. It is used to
+// record and replay the exchanges of a run.
+func WithTransport(ctx context.Context, transport http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportKey, transport)
+}
+
+// Transport returns the transport set with WithTransport, or nil to use the
+// default transport.
+func Transport(ctx context.Context) http.RoundTripper {
+	transport, _ := ctx.Value(transportKey).(http.RoundTripper)
+	return transport
+}
+
+// NodeID returns the ID of the node being executed under ctx.
+func NodeID(ctx context.Context) string {
+	nodeID, _ := ctx.Value(nodeIDKey).(string)
+	return nodeID
+}
+
+// SetHTTPSettings sets the per-host limits of the transport that request
+// nodes share during a run.
+func (e *Executor) SetHTTPSettings(settings *types.HTTPSettings) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.httpSettings = settings
+}
+
+// withRunTransport gives a run its own pooled transport enforcing the host
+// limits, layered over any transport already set on ctx. The returned
+// function closes the pool once the run is over.
+func (e *Executor) withRunTransport(ctx context.Context) (context.Context, func()) {
+	e.mutex.RLock()
+	settings := e.httpSettings
+	e.mutex.RUnlock()
+
+	pool := httppool.New(settings, Transport(ctx))
+	return WithTransport(ctx, pool), pool.CloseIdleConnections
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+func TestExecuteUpTo(t *testing.T) {
+	// a -> b -> target -> after, x -> target, and unrelated y -> z
+	var ran []string
+	var mutex sync.Mutex
+	record := func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		ran = append(ran, NodeID(ctx))
+		return map[string]interface{}{"out": NodeID(ctx)}, nil
+	}
+	var nodes []*funcNode
+	for _, id := range []string{"a", "b", "x", "target", "after", "y", "z"} {
+		nodes = append(nodes, newFuncNode(id, record))
+	}
+	nodes[3].Inputs = append(nodes[3].Inputs, types.NodeInput{Name: "other", Type: "any"})
+	graph := newTestGraph(nodes, [2]string{"a", "b"}, [2]string{"b", "target"}, [2]string{"target", "after"}, [2]string{"y", "z"})
+	if err := graph.AddConnection(types.Connection{ID: "x", SourceNode: "x", SourcePort: "out", TargetNode: "target", TargetPort: "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := NewExecutor(graph).ExecuteUpTo(context.Background(), "target")
+	if err != nil {
+		t.Fatalf("ExecuteUpTo failed: %v", err)
+	}
+
+	sort.Strings(ran)
+	if got := strings.Join(ran, " "); got != "a b target x" {
+		t.Errorf("ran %s, want only the target and its upstream nodes", got)
+	}
+	if len(results) != 4 || results[len(results)-1].NodeID != "target" {
+		t.Errorf("results %s, want the target last", statuses(results))
+	}
+	if got := results[len(results)-1].Inputs; got["in"] != "b" || got["other"] != "x" {
+		t.Errorf("target received %v", got)
+	}
+
+	if _, err := NewExecutor(graph).ExecuteUpTo(context.Background(), "missing"); !errors.Is(err, types.ErrNodeNotFound) {
+		t.Errorf("unknown target returned %v, want ErrNodeNotFound", err)
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+// SetVariables sets the values substituted for {{name}} placeholders in
+// static node inputs. Wired inputs are passed through unchanged.
+func (e *Executor) SetVariables(variables map[string]interface{}) {
+	copied := make(map[string]interface{}, len(variables))
+	for key, value := range variables {
+		copied[key] = value
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.variables = copied
+}
+
+func (e *Executor) Variables() map[string]interface{} {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	copied := make(map[string]interface{}, len(e.variables))
+	for key, value := range e.variables {
+		copied[key] = value
+	}
+	return copied
+}
+
+// SubstituteVariables replaces {{name}} placeholders in strings, including
+// strings nested in maps and lists. A string that is exactly one placeholder
+// takes the variable's value with its original type. Unknown placeholders
+// are left untouched.
+func SubstituteVariables(value interface{}, variables map[string]interface{}) interface{} {
+	if len(variables) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		if match := placeholderPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			if replacement, exists := variables[match[1]]; exists {
+				return replacement
+			}
+			return v
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			if replacement, exists := variables[name]; exists {
+				return fmt.Sprintf("%v", replacement)
+			}
+			return placeholder
+		})
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = SubstituteVariables(item, variables)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = SubstituteVariables(item, variables)
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,108 @@
+package httppool
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// hostLimiter combines a concurrency limit with a token bucket rate limit.
+// Either may be disabled.
+type hostLimiter struct {
+	slots chan struct{}
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+func newHostLimiter(limits types.HostLimits) *hostLimiter {
+	l := &hostLimiter{rate: limits.RateLimit}
+	if limits.MaxConcurrency > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrency)
+	}
+	if l.rate > 0 {
+		l.burst = float64(limits.Burst)
+		if l.burst < 1 {
+			l.burst = 1
+		}
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+	return l
+}
+
+// acquire waits for a concurrency slot and then for a token, and returns
+// how long it had to wait. Every successful acquire must be followed by
+// release.
+func (l *hostLimiter) acquire(ctx context.Context) (time.Duration, error) {
+	var waited time.Duration
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			start := time.Now()
+			select {
+			case l.slots <- struct{}{}:
+				waited = time.Since(start)
+			case <-ctx.Done():
+				return time.Since(start), ctx.Err()
+			}
+		}
+	}
+
+	if delay := l.reserve(); delay > 0 {
+		start := time.Now()
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			waited += time.Since(start)
+		case <-ctx.Done():
+			timer.Stop()
+			l.cancelReservation()
+			l.release()
+			return waited + time.Since(start), ctx.Err()
+		}
+	}
+	return waited, nil
+}
+
+func (l *hostLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// reserve takes a token, going into debt if none is available, and returns
+// how long to wait until the debt is paid off.
+func (l *hostLimiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *hostLimiter) cancelReservation() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.tokens++
+}
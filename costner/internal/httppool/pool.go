@@ -0,0 +1,135 @@
+package httppool
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// DefaultHost is the key of the limits applied to every host that has no
+// entry of its own. Each host still gets its own limiter.
+const DefaultHost = "*"
+
+// Transport is the HTTP transport shared by the request nodes of a run. It
+// keeps connections alive between requests and enforces the per-host
+// concurrency and rate limits of the project settings.
+type Transport struct {
+	base     http.RoundTripper
+	owned    *http.Transport
+	settings map[string]types.HostLimits
+	hosts    map[string]*hostLimiter
+	mutex    sync.Mutex
+}
+
+// New creates a transport sending requests through base. When base is nil a
+// new pooled transport is created, and closed by CloseIdleConnections.
+func New(settings *types.HTTPSettings, base http.RoundTripper) *Transport {
+	t := &Transport{
+		base:  base,
+		hosts: make(map[string]*hostLimiter),
+	}
+	if settings != nil {
+		t.settings = settings.Hosts
+	}
+	if base == nil {
+		t.owned = http.DefaultTransport.(*http.Transport).Clone()
+		t.owned.MaxIdleConnsPerHost = 64
+		t.base = t.owned
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.limiter(req.URL.Host)
+	if limiter == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	waited, err := limiter.acquire(req.Context())
+	addWait(req.Context(), waited)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		limiter.release()
+		return nil, err
+	}
+
+	// The concurrency slot is held until the response body is closed
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: limiter.release}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the pool created by
+// New. It should be called when the run is finished.
+func (t *Transport) CloseIdleConnections() {
+	if t.owned != nil {
+		t.owned.CloseIdleConnections()
+	}
+}
+
+func (t *Transport) limiter(host string) *hostLimiter {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if limiter, exists := t.hosts[host]; exists {
+		return limiter
+	}
+
+	limits, exists := t.settings[host]
+	if !exists {
+		limits, exists = t.settings[DefaultHost]
+	}
+	var limiter *hostLimiter
+	if exists {
+		limiter = newHostLimiter(limits)
+	}
+	t.hosts[host] = limiter
+	return limiter
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+type waitKey struct{}
+
+type waitCounter struct {
+	total time.Duration
+	mutex sync.Mutex
+}
+
+// TrackWait returns a context that accumulates the time requests sent with
+// it spend waiting on limits, and a function reporting that time.
+func TrackWait(ctx context.Context) (context.Context, func() time.Duration) {
+	counter := &waitCounter{}
+	return context.WithValue(ctx, waitKey{}, counter), func() time.Duration {
+		counter.mutex.Lock()
+		defer counter.mutex.Unlock()
+		return counter.total
+	}
+}
+
+func addWait(ctx context.Context, wait time.Duration) {
+	counter, ok := ctx.Value(waitKey{}).(*waitCounter)
+	if !ok {
+		return
+	}
+	counter.mutex.Lock()
+	counter.total += wait
+	counter.mutex.Unlock()
+}
@@ -0,0 +1,173 @@
+package httppool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"costner/pkg/types"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func okResponse(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+}
+
+func send(t *testing.T, transport http.RoundTripper, ctx context.Context, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transport.RoundTrip(req)
+}
+
+func settings(hosts map[string]types.HostLimits) *types.HTTPSettings {
+	return &types.HTTPSettings{Hosts: hosts}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	var inFlight, peak int32
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return okResponse(req)
+	})
+	transport := New(settings(map[string]types.HostLimits{"api": {MaxConcurrency: 2}}), base)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := send(t, transport, context.Background(), "http://api/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("%d requests ran at once, want at most 2", peak)
+	}
+}
+
+func TestSlotHeldUntilBodyClosed(t *testing.T) {
+	transport := New(settings(map[string]types.HostLimits{"api": {MaxConcurrency: 1}}), roundTripFunc(okResponse))
+
+	first, err := send(t, transport, context.Background(), "http://api/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := send(t, transport, ctx, "http://api/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v while the first body was open, want the context error", err)
+	}
+
+	first.Body.Close()
+	// Closing twice must not free a second slot
+	first.Body.Close()
+	second, err := send(t, transport, context.Background(), "http://api/")
+	if err != nil {
+		t.Fatalf("request after the body was closed failed: %v", err)
+	}
+	second.Body.Close()
+}
+
+func TestRateLimit(t *testing.T) {
+	transport := New(settings(map[string]types.HostLimits{"api": {RateLimit: 20, Burst: 2}}), roundTripFunc(okResponse))
+	ctx, waited := TrackWait(context.Background())
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		resp, err := send(t, transport, ctx, "http://api/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if i == 1 && time.Since(start) > 40*time.Millisecond {
+			t.Errorf("the burst of 2 requests was delayed by %v", time.Since(start))
+		}
+	}
+
+	// Two requests beyond the burst at 20 per second take 100ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 100ms", elapsed)
+	}
+	if wait := waited(); wait < 90*time.Millisecond {
+		t.Errorf("tracked wait is %v, want at least 100ms", wait)
+	}
+}
+
+func TestDefaultHostLimits(t *testing.T) {
+	transport := New(settings(map[string]types.HostLimits{
+		DefaultHost: {MaxConcurrency: 1},
+		"free":      {},
+	}), roundTripFunc(okResponse))
+
+	held, err := send(t, transport, context.Background(), "http://a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Body.Close()
+
+	// Each host gets its own limiter from the default limits
+	other, err := send(t, transport, context.Background(), "http://b/")
+	if err != nil {
+		t.Fatalf("request to another host failed: %v", err)
+	}
+	other.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := send(t, transport, ctx, "http://a/"); err == nil {
+		t.Error("second request to a was not limited by the default limits")
+	}
+
+	// A host with its own entry does not use the defaults
+	for i := 0; i < 2; i++ {
+		resp, err := send(t, transport, ctx, "http://free/")
+		if err != nil {
+			t.Fatalf("request to a host without limits failed: %v", err)
+		}
+		defer resp.Body.Close()
+	}
+}
+
+func TestNoSettingsIsUnlimited(t *testing.T) {
+	transport := New(nil, roundTripFunc(okResponse))
+	ctx, waited := TrackWait(context.Background())
+	for i := 0; i < 10; i++ {
+		resp, err := send(t, transport, ctx, "http://api/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+	}
+	if wait := waited(); wait != 0 {
+		t.Errorf("tracked wait is %v without limits", wait)
+	}
+}
@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// DefaultHistory is the number of runs a Monitor keeps unless configured
+// otherwise.
+const DefaultHistory = 100
+
+type Options struct {
+	Schedule Schedule
+	// History is the number of most recent runs kept for the status page.
+	History int
+	// Timeout bounds a single run; zero means no limit.
+	Timeout time.Duration
+}
+
+// RunRecord is the outcome of one monitor run.
+type RunRecord struct {
+	Number   int           `json:"number"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Nodes    []NodeRun     `json:"nodes"`
+}
+
+type NodeRun struct {
+	NodeID     string                `json:"node_id"`
+	Status     types.ExecutionStatus `json:"status"`
+	Duration   time.Duration         `json:"duration"`
+	Error      string                `json:"error,omitempty"`
+	SkipReason string                `json:"skip_reason,omitempty"`
+}
+
+// Monitor runs a graph on a schedule and keeps a rolling history of the
+// results. Runs never overlap: schedule ticks that fall within a run still
+// in progress are skipped and counted.
+type Monitor struct {
+	name     string
+	executor *core.Executor
+	opts     Options
+
+	history  []RunRecord
+	runs     int
+	failures int
+	skipped  int
+	running  bool
+	started  time.Time
+	next     time.Time
+	onRun    func(RunRecord)
+	mutex    sync.RWMutex
+}
+
+func New(name string, executor *core.Executor, opts Options) *Monitor {
+	if opts.History < 1 {
+		opts.History = DefaultHistory
+	}
+	return &Monitor{
+		name:     name,
+		executor: executor,
+		opts:     opts,
+	}
+}
+
+// OnRun registers a callback invoked after every run.
+func (m *Monitor) OnRun(callback func(RunRecord)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onRun = callback
+}
+
+// Run executes the graph once immediately and then at every schedule tick
+// until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) error {
+	m.mutex.Lock()
+	m.started = time.Now()
+	m.mutex.Unlock()
+
+	next := time.Now()
+	for {
+		m.setNext(next)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		m.runOnce(ctx, start)
+
+		// Drop the ticks that passed while the run was in progress
+		next = m.opts.Schedule.Next(start)
+		skipped := 0
+		for !next.After(time.Now()) {
+			next = m.opts.Schedule.Next(next)
+			skipped++
+		}
+		if skipped > 0 {
+			m.mutex.Lock()
+			m.skipped += skipped
+			m.mutex.Unlock()
+		}
+	}
+}
+
+func (m *Monitor) runOnce(ctx context.Context, start time.Time) {
+	m.mutex.Lock()
+	m.running = true
+	m.mutex.Unlock()
+
+	runCtx := ctx
+	if m.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, m.opts.Timeout)
+		defer cancel()
+	}
+
+	results, err := m.executor.ExecuteGraph(runCtx)
+
+	// A run interrupted by stopping the monitor is not recorded
+	if ctx.Err() != nil {
+		m.mutex.Lock()
+		m.running = false
+		m.mutex.Unlock()
+		return
+	}
+
+	record := RunRecord{
+		Start:    start,
+		Duration: time.Since(start),
+		Success:  err == nil,
+		Nodes:    make([]NodeRun, 0, len(results)),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	for _, result := range results {
+		record.Nodes = append(record.Nodes, NodeRun{
+			NodeID:     result.NodeID,
+			Status:     result.Status,
+			Duration:   result.Duration,
+			Error:      result.Error,
+			SkipReason: result.SkipReason,
+		})
+	}
+
+	m.mutex.Lock()
+	m.running = false
+	m.runs++
+	record.Number = m.runs
+	if !record.Success {
+		m.failures++
+	}
+	m.history = append(m.history, record)
+	if len(m.history) > m.opts.History {
+		m.history = m.history[len(m.history)-m.opts.History:]
+	}
+	onRun := m.onRun
+	m.mutex.Unlock()
+
+	if onRun != nil {
+		onRun(record)
+	}
+}
+
+func (m *Monitor) setNext(next time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.next = next
+}
@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// testNode runs fn with the number of times it has been executed.
+type testNode struct {
+	types.BaseNode
+	calls *int32
+	fn    func(call int) error
+}
+
+func newTestNode(id string, fn func(call int) error) *testNode {
+	return &testNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: "test",
+			Inputs:   []types.NodeInput{{Name: "in", Type: "any"}},
+			Outputs:  []types.NodeOutput{{Name: "out", Type: "any"}},
+		},
+		calls: new(int32),
+		fn:    fn,
+	}
+}
+
+func (n *testNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	call := int(atomic.AddInt32(n.calls, 1))
+	if n.fn != nil {
+		if err := n.fn(call); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{"out": n.NodeID}, nil
+}
+
+func (n *testNode) Clone() types.Node {
+	clone := *n
+	return &clone
+}
+
+// runMonitor runs m until it has recorded runs runs and returns them.
+func runMonitor(t *testing.T, m *Monitor, runs int) []RunRecord {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var records []RunRecord
+	m.OnRun(func(record RunRecord) {
+		records = append(records, record)
+		if len(records) == runs {
+			cancel()
+		}
+	})
+	if err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+	if len(records) != runs {
+		t.Fatalf("monitor recorded %d runs, want %d", len(records), runs)
+	}
+	return records
+}
+
+func TestMonitorRunsDoNotOverlap(t *testing.T) {
+	const runTime = 30 * time.Millisecond
+	var running, peak int32
+	graph := core.NewGraph()
+	graph.AddNode(newTestNode("slow", func(int) error {
+		n := atomic.AddInt32(&running, 1)
+		if n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		time.Sleep(runTime)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}))
+
+	m := New("overlap", core.NewExecutor(graph), Options{Schedule: Every(10 * time.Millisecond)})
+	records := runMonitor(t, m, 3)
+
+	if peak != 1 {
+		t.Errorf("%d runs were in progress at once", peak)
+	}
+	for i := 1; i < len(records); i++ {
+		if gap := records[i].Start.Sub(records[i-1].Start); gap < runTime {
+			t.Errorf("run %d started %v after run %d, before it finished", i+1, gap, i)
+		}
+	}
+	if skipped := m.Status().SkippedTicks; skipped < 2 {
+		t.Errorf("%d ticks skipped, want the ticks during each run to be skipped", skipped)
+	}
+}
+
+func TestMonitorKeepsGoingAfterFailure(t *testing.T) {
+	graph := core.NewGraph()
+	graph.AddNode(newTestNode("flaky", func(call int) error {
+		if call == 1 {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	graph.AddNode(newTestNode("after", nil))
+	graph.AddNode(newTestNode("other", nil))
+	graph.AddConnection(types.Connection{ID: "c", SourceNode: "flaky", SourcePort: "out", TargetNode: "after", TargetPort: "in"})
+
+	executor := core.NewExecutor(graph)
+	executor.SetKeepGoing(true)
+	m := New("flaky", executor, Options{Schedule: Every(5 * time.Millisecond)})
+
+	// Check the health endpoint as each run is recorded
+	handler := m.Handler()
+	var health []int
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m.OnRun(func(record RunRecord) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+		health = append(health, recorder.Code)
+		if len(health) == 2 {
+			cancel()
+		}
+	})
+	m.Run(ctx)
+
+	if len(health) != 2 || health[0] != http.StatusServiceUnavailable || health[1] != http.StatusOK {
+		t.Errorf("health after each run: %v, want 503 then 200", health)
+	}
+
+	status := m.Status()
+	if status.Runs != 2 || status.Failures != 1 || !status.Healthy {
+		t.Errorf("status: %d runs, %d failures, healthy %v; want 2, 1, true", status.Runs, status.Failures, status.Healthy)
+	}
+
+	first := status.History[1]
+	statuses := make(map[string]types.ExecutionStatus)
+	for _, node := range first.Nodes {
+		statuses[node.NodeID] = node.Status
+	}
+	want := map[string]types.ExecutionStatus{"flaky": types.StatusFailed, "after": types.StatusSkipped, "other": types.StatusSuccess}
+	for nodeID, status := range want {
+		if statuses[nodeID] != status {
+			t.Errorf("first run: %s is %s, want %s", nodeID, statuses[nodeID], status)
+		}
+	}
+}
+
+func TestMonitorHandler(t *testing.T) {
+	graph := core.NewGraph()
+	graph.AddNode(newTestNode("flaky", func(call int) error {
+		if call == 2 {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	graph.AddNode(newTestNode("after", nil))
+	graph.AddConnection(types.Connection{ID: "c", SourceNode: "flaky", SourcePort: "out", TargetNode: "after", TargetPort: "in"})
+
+	m := New("shop", core.NewExecutor(graph), Options{Schedule: Every(5 * time.Millisecond), History: 2})
+	runMonitor(t, m, 3)
+	handler := m.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		return recorder
+	}
+
+	response := get("/status.json")
+	if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("status.json content type %q", contentType)
+	}
+	var status Status
+	if err := json.Unmarshal(response.Body.Bytes(), &status); err != nil {
+		t.Fatalf("status.json: %v", err)
+	}
+	if status.Project != "shop" || status.Schedule != "every 5ms" || status.Runs != 3 || status.Failures != 1 || !status.Healthy {
+		t.Errorf("status = %+v", status)
+	}
+
+	// Only the last two runs are kept, newest first
+	if len(status.History) != 2 || status.History[0].Number != 3 || status.History[1].Number != 2 {
+		t.Fatalf("history has runs %+v, want 3 and 2", status.History)
+	}
+	if len(status.Nodes) != 2 {
+		t.Fatalf("nodes = %+v", status.Nodes)
+	}
+	flaky, after := status.Nodes[0], status.Nodes[1]
+	if flaky.NodeID != "flaky" || flaky.Passed != 1 || flaky.Failed != 1 || flaky.LastStatus != types.StatusSuccess {
+		t.Errorf("flaky = %+v, want one pass and one failure", flaky)
+	}
+	if after.NodeID != "after" || after.Passed != 1 || after.Skipped != 1 {
+		t.Errorf("after = %+v, want one pass and one skip", after)
+	}
+
+	if code := get("/healthz").Code; code != http.StatusOK {
+		t.Errorf("healthz returned %d", code)
+	}
+	if page := get("/"); page.Code != http.StatusOK || !strings.Contains(page.Body.String(), "<h1>shop</h1>") {
+		t.Errorf("status page returned %d:\n%s", page.Code, page.Body.String())
+	}
+	if code := get("/missing").Code; code != http.StatusNotFound {
+		t.Errorf("unknown path returned %d", code)
+	}
+}
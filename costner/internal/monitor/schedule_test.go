@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Friday 2024-03-01 10:07
+	start := time.Date(2024, 3, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "* * * * *", want: "2024-03-01 10:08"},
+		{expr: "*/5 * * * *", want: "2024-03-01 10:10"},
+		{expr: "0 * * * *", want: "2024-03-01 11:00"},
+		{expr: "30 9 * * *", want: "2024-03-02 09:30"},
+		{expr: "0 0 15 * *", want: "2024-03-15 00:00"},
+		{expr: "0 0 * * 1", want: "2024-03-04 00:00"},
+		{expr: "0 0 * * 7", want: "2024-03-03 00:00"},
+		{expr: "0 0 1-5/2 * *", want: "2024-03-03 00:00"},
+		{expr: "0 12 * 6 *", want: "2024-06-01 12:00"},
+		// Both day fields restricted: either one matches
+		{expr: "0 0 15 * 1", want: "2024-03-04 00:00"},
+		// A day field starting with * is combined with the other one
+		{expr: "0 0 */2 * 1", want: "2024-03-11 00:00"},
+		{expr: "0 0 10 * */3", want: "2024-03-10 00:00"},
+		{expr: "0 0 11 * */3", want: "2024-05-11 00:00"},
+		{expr: "@daily", want: "2024-03-02 00:00"},
+		{expr: "@monthly", want: "2024-04-01 00:00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			schedule, err := ParseCron(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(start).Format("2006-01-02 15:04"); got != test.want {
+				t.Errorf("Next = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"0 0 30 2 *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// Status is the current state of a Monitor as served by its JSON endpoint.
+// History is ordered newest first.
+type Status struct {
+	Project      string       `json:"project"`
+	Schedule     string       `json:"schedule"`
+	Healthy      bool         `json:"healthy"`
+	Running      bool         `json:"running"`
+	Started      time.Time    `json:"started"`
+	NextRun      time.Time    `json:"next_run"`
+	Runs         int          `json:"runs"`
+	Failures     int          `json:"failures"`
+	SkippedTicks int          `json:"skipped_ticks"`
+	LastRun      *RunRecord   `json:"last_run,omitempty"`
+	Nodes        []NodeStatus `json:"nodes"`
+	History      []RunRecord  `json:"history"`
+}
+
+// NodeStatus summarises one node over the runs in the history.
+type NodeStatus struct {
+	NodeID      string                `json:"node_id"`
+	LastStatus  types.ExecutionStatus `json:"last_status"`
+	Passed      int                   `json:"passed"`
+	Failed      int                   `json:"failed"`
+	Skipped     int                   `json:"skipped"`
+	AvgDuration time.Duration         `json:"avg_duration"`
+	MaxDuration time.Duration         `json:"max_duration"`
+	LastError   string                `json:"last_error,omitempty"`
+}
+
+func (m *Monitor) Status() Status {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := Status{
+		Project:      m.name,
+		Schedule:     m.opts.Schedule.String(),
+		Running:      m.running,
+		Started:      m.started,
+		NextRun:      m.next,
+		Runs:         m.runs,
+		Failures:     m.failures,
+		SkippedTicks: m.skipped,
+		History:      make([]RunRecord, 0, len(m.history)),
+	}
+	for i := len(m.history) - 1; i >= 0; i-- {
+		status.History = append(status.History, m.history[i])
+	}
+	if len(status.History) > 0 {
+		last := status.History[0]
+		status.LastRun = &last
+		status.Healthy = last.Success
+	}
+
+	// Aggregate per node, keeping the node order of the latest run. Skipped
+	// nodes are counted but do not contribute durations.
+	index := make(map[string]int)
+	totals := make(map[string]time.Duration)
+	for _, record := range status.History {
+		for _, node := range record.Nodes {
+			i, seen := index[node.NodeID]
+			if !seen {
+				i = len(status.Nodes)
+				index[node.NodeID] = i
+				lastError := node.Error
+				if node.Status == types.StatusSkipped {
+					lastError = node.SkipReason
+				}
+				status.Nodes = append(status.Nodes, NodeStatus{
+					NodeID:     node.NodeID,
+					LastStatus: node.Status,
+					LastError:  lastError,
+				})
+			}
+			summary := &status.Nodes[i]
+			switch node.Status {
+			case types.StatusSuccess:
+				summary.Passed++
+			case types.StatusSkipped:
+				summary.Skipped++
+				continue
+			default:
+				summary.Failed++
+			}
+			totals[node.NodeID] += node.Duration
+			if node.Duration > summary.MaxDuration {
+				summary.MaxDuration = node.Duration
+			}
+		}
+	}
+	for i := range status.Nodes {
+		node := &status.Nodes[i]
+		if executed := node.Passed + node.Failed; executed > 0 {
+			node.AvgDuration = totals[node.NodeID] / time.Duration(executed)
+		}
+	}
+	return status
+}
+
+// Handler serves the status page at /, the status as JSON at /status.json
+// and a health check at /healthz that fails while the last run failed.
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(m.Status())
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := m.Status()
+		if status.LastRun != nil && !status.Healthy {
+			http.Error(w, "failing", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		statusPage.Execute(w, m.Status())
+	})
+	return mux
+}
+
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	},
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="10">
+<title>{{.Project}} - Costner monitor</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 4px 12px; text-align: left; border-bottom: 1px solid #ddd; }
+.success { color: #2a7d2a; }
+.failed, .timed_out { color: #c0392b; }
+.skipped { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Project}}</h1>
+<p>
+{{if not .LastRun}}Waiting for the first run{{else if .Healthy}}<span class="success">Healthy</span>{{else}}<span class="failed">Failing</span>{{end}}
+{{if .Running}}(run in progress){{end}}
+</p>
+<p>Schedule: {{.Schedule}}. Next run: {{clock .NextRun}}. Runs: {{.Runs}}, failures: {{.Failures}}, skipped ticks: {{.SkippedTicks}}.</p>
+<h2>Nodes</h2>
+<table>
+<tr><th>Node</th><th>Last status</th><th>Passed</th><th>Failed</th><th>Skipped</th><th>Avg</th><th>Max</th><th>Last error</th></tr>
+{{range .Nodes}}<tr><td>{{.NodeID}}</td><td class="{{.LastStatus}}">{{.LastStatus}}</td><td>{{.Passed}}</td><td>{{.Failed}}</td><td>{{.Skipped}}</td><td>{{ms .AvgDuration}}</td><td>{{ms .MaxDuration}}</td><td>{{.LastError}}</td></tr>
+{{end}}</table>
+<h2>History</h2>
+<table>
+<tr><th>#</th><th>Started</th><th>Duration</th><th>Result</th></tr>
+{{range .History}}<tr><td>{{.Number}}</td><td>{{clock .Start}}</td><td>{{ms .Duration}}</td><td>{{if .Success}}<span class="success">passed</span>{{else}}<span class="failed">{{.Error}}</span>{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,514 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+type compositeEntry struct {
+	definition types.CompositeDefinition
+	inputs     []types.NodeInput
+	outputs    []types.NodeOutput
+	version    int
+}
+
+// RegisterComposite adds a composite node type, or replaces the definition
+// of an existing one. Existing instances pick up the new ports and inner
+// graph the next time they are used, and their cached results are no
+// longer reused.
+func (f *NodeFactory) RegisterComposite(definition types.CompositeDefinition) error {
+	if definition.Name == "" {
+		return fmt.Errorf("composite name is required")
+	}
+	for _, builtin := range builtinNodeTypes {
+		if definition.Name == builtin {
+			return fmt.Errorf("composite name %s is a built-in node type", definition.Name)
+		}
+	}
+	if _, err := f.sortComposites([]types.CompositeDefinition{definition}); err != nil {
+		return err
+	}
+
+	graph, err := f.buildSubgraph(definition.Subgraph)
+	if err != nil {
+		return fmt.Errorf("invalid composite %s: %w", definition.Name, err)
+	}
+
+	inputs, err := f.compositeInputs(definition, graph)
+	if err != nil {
+		return fmt.Errorf("invalid composite %s: %w", definition.Name, err)
+	}
+	outputs, err := f.compositeOutputs(definition, graph)
+	if err != nil {
+		return fmt.Errorf("invalid composite %s: %w", definition.Name, err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.version++
+	f.composites[definition.Name] = &compositeEntry{
+		definition: definition,
+		inputs:     inputs,
+		outputs:    outputs,
+		version:    f.version,
+	}
+	return nil
+}
+
+// RegisterComposites registers several composite types that may contain
+// each other, each one after the composites it contains, so the order of
+// definitions does not matter.
+func (f *NodeFactory) RegisterComposites(definitions []types.CompositeDefinition) error {
+	ordered, err := f.sortComposites(definitions)
+	if err != nil {
+		return err
+	}
+	for _, definition := range ordered {
+		if err := f.RegisterComposite(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *NodeFactory) GetComposite(name string) (types.CompositeDefinition, bool) {
+	definition, _, exists := f.composite(name)
+	return definition, exists
+}
+
+// GetComposites returns the registered definitions, each one after the
+// composites it contains, so they can be registered again in this order.
+func (f *NodeFactory) GetComposites() []types.CompositeDefinition {
+	definitions := make([]types.CompositeDefinition, 0)
+	for _, name := range f.CompositeNames() {
+		if definition, exists := f.GetComposite(name); exists {
+			definitions = append(definitions, definition)
+		}
+	}
+	if ordered, err := f.sortComposites(definitions); err == nil {
+		return ordered
+	}
+	return definitions
+}
+
+// sortComposites orders definitions so that every composite comes after the
+// composites it contains. Composite types that are not in definitions are
+// looked up among the registered ones. It fails if a composite contains
+// itself, directly or through other composites.
+func (f *NodeFactory) sortComposites(definitions []types.CompositeDefinition) ([]types.CompositeDefinition, error) {
+	pending := make(map[string]types.CompositeDefinition, len(definitions))
+	for _, definition := range definitions {
+		pending[definition.Name] = definition
+	}
+
+	ordered := make([]types.CompositeDefinition, 0, len(definitions))
+	done := make(map[string]bool)
+	path := make([]string, 0)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		for i, entered := range path {
+			if entered == name {
+				chain := append(append([]string{}, path[i:]...), name)
+				return fmt.Errorf("composite %s cannot contain itself: %s", name, strings.Join(chain, " -> "))
+			}
+		}
+		if done[name] {
+			return nil
+		}
+
+		definition, listed := pending[name]
+		if !listed {
+			registered, _, exists := f.composite(name)
+			if !exists {
+				return nil
+			}
+			definition = registered
+		}
+
+		path = append(path, name)
+		for _, nodeType := range subgraphNodeTypes(definition.Subgraph) {
+			if err := visit(nodeType); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+
+		done[name] = true
+		if listed {
+			ordered = append(ordered, definition)
+		}
+		return nil
+	}
+
+	for _, definition := range definitions {
+		if err := visit(definition.Name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func (f *NodeFactory) composite(name string) (types.CompositeDefinition, int, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	entry, exists := f.composites[name]
+	if !exists {
+		return types.CompositeDefinition{}, 0, false
+	}
+	return entry.definition, entry.version, true
+}
+
+// compositesVersion changes whenever a composite definition is registered.
+// Nodes that run composites report it as their version, so that their cached
+// results are not reused after a definition they may contain is edited.
+func (f *NodeFactory) compositesVersion() int {
+	if f == nil {
+		return 0
+	}
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.version
+}
+
+func (f *NodeFactory) compositePorts(name string) ([]types.NodeInput, []types.NodeOutput, int) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	entry, exists := f.composites[name]
+	if !exists {
+		return nil, nil, 0
+	}
+	inputs := make([]types.NodeInput, len(entry.inputs))
+	copy(inputs, entry.inputs)
+	outputs := make([]types.NodeOutput, len(entry.outputs))
+	copy(outputs, entry.outputs)
+	return inputs, outputs, entry.version
+}
+
+// compositeInputs resolves exposed inputs to inner input ports. Only ports
+// that are not wired inside the composite can be exposed.
+func (f *NodeFactory) compositeInputs(definition types.CompositeDefinition, graph *core.Graph) ([]types.NodeInput, error) {
+	connected := make(map[types.ConnectionPoint]bool)
+	for _, conn := range definition.Connections {
+		connected[types.ConnectionPoint{NodeID: conn.TargetNode, Port: conn.TargetPort}] = true
+	}
+
+	names := make(map[string]bool)
+	inputs := make([]types.NodeInput, 0, len(definition.Inputs))
+	for _, port := range definition.Inputs {
+		if port.Name == "" || names[port.Name] {
+			return nil, fmt.Errorf("input names must be unique and non-empty: %q", port.Name)
+		}
+		names[port.Name] = true
+
+		node, exists := graph.GetNode(port.Node)
+		if !exists {
+			return nil, fmt.Errorf("input %s: node not found: %s", port.Name, port.Node)
+		}
+		if connected[types.ConnectionPoint{NodeID: port.Node, Port: port.Port}] {
+			return nil, fmt.Errorf("input %s: port %s.%s is already connected", port.Name, port.Node, port.Port)
+		}
+
+		found := false
+		for _, input := range node.GetInputs() {
+			if input.Name == port.Port {
+				input.Name = port.Name
+				inputs = append(inputs, input)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("input %s: port not found: %s.%s", port.Name, port.Node, port.Port)
+		}
+	}
+	return inputs, nil
+}
+
+func (f *NodeFactory) compositeOutputs(definition types.CompositeDefinition, graph *core.Graph) ([]types.NodeOutput, error) {
+	names := make(map[string]bool)
+	outputs := make([]types.NodeOutput, 0, len(definition.Outputs))
+	for _, port := range definition.Outputs {
+		if port.Name == "" || names[port.Name] {
+			return nil, fmt.Errorf("output names must be unique and non-empty: %q", port.Name)
+		}
+		names[port.Name] = true
+
+		node, exists := graph.GetNode(port.Node)
+		if !exists {
+			return nil, fmt.Errorf("output %s: node not found: %s", port.Name, port.Node)
+		}
+
+		found := false
+		for _, output := range node.GetOutputs() {
+			if output.Name == port.Port {
+				output.Name = port.Name
+				output.Value = nil
+				outputs = append(outputs, output)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("output %s: port not found: %s.%s", port.Name, port.Node, port.Port)
+		}
+	}
+	return outputs, nil
+}
+
+// CollapseToComposite replaces the given nodes with a single instance of a
+// new composite type, placed at the position of the first node. Ports listed
+// in inputs and outputs are exposed under their chosen names; inner ports
+// wired to nodes outside the selection are exposed automatically as
+// "<node>_<port>" and rewired to the new instance. The edit is made through
+// history as one transaction, so it can be undone and leaves the graph
+// unchanged if it fails.
+func (f *NodeFactory) CollapseToComposite(history *core.History, name, instanceID string, nodeIDs []string, inputs, outputs []types.CompositePort) (types.Node, error) {
+	graph := history.Graph()
+	if len(nodeIDs) == 0 {
+		return nil, fmt.Errorf("no nodes selected")
+	}
+	if _, exists := f.GetComposite(name); exists {
+		return nil, fmt.Errorf("composite %s already exists", name)
+	}
+
+	selected := make(map[string]bool)
+	definition := types.CompositeDefinition{
+		Name:    name,
+		Inputs:  append([]types.CompositePort{}, inputs...),
+		Outputs: append([]types.CompositePort{}, outputs...),
+	}
+
+	for _, nodeID := range nodeIDs {
+		node, exists := graph.GetNode(nodeID)
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", types.ErrNodeNotFound, nodeID)
+		}
+		selected[nodeID] = true
+		definition.Nodes = append(definition.Nodes, nodeToData(node))
+	}
+	if _, exists := graph.GetNode(instanceID); exists && !selected[instanceID] {
+		return nil, fmt.Errorf("node already exists: %s", instanceID)
+	}
+
+	exposedInput := make(map[types.ConnectionPoint]string)
+	for _, port := range definition.Inputs {
+		exposedInput[types.ConnectionPoint{NodeID: port.Node, Port: port.Port}] = port.Name
+	}
+	exposedOutput := make(map[types.ConnectionPoint]string)
+	for _, port := range definition.Outputs {
+		exposedOutput[types.ConnectionPoint{NodeID: port.Node, Port: port.Port}] = port.Name
+	}
+
+	// Split connections into inner ones and ones crossing the boundary
+	boundary := make([]types.Connection, 0)
+	for _, conn := range graph.GetConnections() {
+		sourceInside, targetInside := selected[conn.SourceNode], selected[conn.TargetNode]
+		switch {
+		case sourceInside && targetInside:
+			definition.Connections = append(definition.Connections, conn)
+		case targetInside:
+			point := types.ConnectionPoint{NodeID: conn.TargetNode, Port: conn.TargetPort}
+			if _, exists := exposedInput[point]; !exists {
+				exposedInput[point] = fmt.Sprintf("%s_%s", conn.TargetNode, conn.TargetPort)
+				definition.Inputs = append(definition.Inputs, types.CompositePort{Name: exposedInput[point], Node: point.NodeID, Port: point.Port})
+			}
+			conn.TargetNode = instanceID
+			conn.TargetPort = exposedInput[point]
+			boundary = append(boundary, conn)
+		case sourceInside:
+			point := types.ConnectionPoint{NodeID: conn.SourceNode, Port: conn.SourcePort}
+			if _, exists := exposedOutput[point]; !exists {
+				exposedOutput[point] = fmt.Sprintf("%s_%s", conn.SourceNode, conn.SourcePort)
+				definition.Outputs = append(definition.Outputs, types.CompositePort{Name: exposedOutput[point], Node: point.NodeID, Port: point.Port})
+			}
+			conn.SourceNode = instanceID
+			conn.SourcePort = exposedOutput[point]
+			boundary = append(boundary, conn)
+		}
+	}
+
+	if err := f.RegisterComposite(definition); err != nil {
+		return nil, err
+	}
+
+	instance, err := f.CreateNode(name, instanceID)
+	if err != nil {
+		f.unregisterComposite(name)
+		return nil, err
+	}
+	if first, exists := graph.GetNode(nodeIDs[0]); exists {
+		instance.SetPosition(first.GetPosition())
+	}
+
+	err = history.Transaction(fmt.Sprintf("collapse to composite %s", name), func() error {
+		for _, nodeID := range nodeIDs {
+			if err := history.RemoveNode(nodeID); err != nil {
+				return err
+			}
+		}
+		if err := history.AddNode(instance); err != nil {
+			return err
+		}
+		for _, conn := range boundary {
+			if err := history.AddConnection(conn); err != nil {
+				return fmt.Errorf("failed to rewire connection %s: %w", conn.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		f.unregisterComposite(name)
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (f *NodeFactory) unregisterComposite(name string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.composites, name)
+}
+
+// CompositeNode is an instance of a registered composite definition. Its
+// ports follow the current definition, so edits propagate to all instances.
+type CompositeNode struct {
+	types.BaseNode
+	factory *NodeFactory
+	version int
+	mutex   sync.Mutex
+}
+
+func newCompositeNode(factory *NodeFactory, nodeType, id string) *CompositeNode {
+	node := &CompositeNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: nodeType,
+			NodeName: nodeType,
+			Config:   make(map[string]interface{}),
+		},
+		factory: factory,
+	}
+	node.syncPorts()
+	return node
+}
+
+// syncPorts refreshes the ports from the definition if it changed since the
+// last call, keeping the values of inputs that still exist. The caller must
+// hold the node mutex, except during construction.
+func (n *CompositeNode) syncPorts() {
+	inputs, outputs, version := n.factory.compositePorts(n.NodeType)
+	if version == 0 || version == n.version {
+		return
+	}
+
+	values := make(map[string]interface{})
+	for _, input := range n.Inputs {
+		values[input.Name] = input.Value
+	}
+	for i := range inputs {
+		if value, exists := values[inputs[i].Name]; exists {
+			inputs[i].Value = value
+		}
+	}
+
+	n.Inputs = inputs
+	n.Outputs = outputs
+	n.version = version
+}
+
+func (n *CompositeNode) GetInputs() []types.NodeInput {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.syncPorts()
+	return n.BaseNode.GetInputs()
+}
+
+func (n *CompositeNode) GetOutputs() []types.NodeOutput {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.syncPorts()
+	return n.BaseNode.GetOutputs()
+}
+
+func (n *CompositeNode) SetInputValue(name string, value interface{}) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.syncPorts()
+	return n.BaseNode.SetInputValue(name, value)
+}
+
+func (n *CompositeNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	definition, _, exists := n.factory.composite(n.NodeType)
+	if !exists {
+		return nil, fmt.Errorf("composite definition not found: %s", n.NodeType)
+	}
+
+	graph, err := n.factory.buildSubgraph(definition.Subgraph)
+	if err != nil {
+		return nil, fmt.Errorf("composite %s: %w", definition.Name, err)
+	}
+
+	// Feed exposed inputs to the inner ports
+	for _, port := range definition.Inputs {
+		if value, exists := inputs[port.Name]; exists {
+			if err := graph.SetInputValue(port.Node, port.Port, value); err != nil {
+				return nil, fmt.Errorf("composite %s: input %s: %w", definition.Name, port.Name, err)
+			}
+		}
+	}
+
+	_, outputs, err := core.RunSubgraph(ctx, graph)
+	if err != nil {
+		return nil, fmt.Errorf("composite %s: %w", definition.Name, err)
+	}
+
+	result := make(map[string]interface{})
+	for _, port := range definition.Outputs {
+		if nodeOutputs, exists := outputs[port.Node]; exists {
+			if value, exists := nodeOutputs[port.Port]; exists {
+				result[port.Name] = value
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (n *CompositeNode) Version() int {
+	return n.factory.compositesVersion()
+}
+
+func (n *CompositeNode) HasSideEffects() bool {
+	definition, _, exists := n.factory.composite(n.NodeType)
+	if !exists {
+		return false
+	}
+	return n.factory.subgraphHasSideEffects(definition.Subgraph)
+}
+
+func (n *CompositeNode) Clone() types.Node {
+	clone := newCompositeNode(n.factory, n.NodeType, n.NodeID)
+	clone.NodeName = n.NodeName
+	clone.Position = n.Position
+	clone.Config = make(map[string]interface{})
+	for k, v := range n.Config {
+		clone.Config[k] = v
+	}
+	return clone
+}
+
+func (n *CompositeNode) Serialize() ([]byte, error) {
+	return json.Marshal(n.BaseNode)
+}
+
+func (n *CompositeNode) Deserialize(data []byte) error {
+	return json.Unmarshal(data, &n.BaseNode)
+}
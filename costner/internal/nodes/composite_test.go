@@ -0,0 +1,284 @@
+package nodes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// wrapper returns a composite whose single node is an instance of inner,
+// exposing its input and output.
+func wrapper(name, inner string) types.CompositeDefinition {
+	return types.CompositeDefinition{
+		Name: name,
+		Subgraph: types.Subgraph{
+			Nodes: []types.NodeData{{ID: "i", Type: inner}},
+		},
+		Inputs:  []types.CompositePort{{Name: "input", Node: "i", Port: "input"}},
+		Outputs: []types.CompositePort{{Name: "output", Node: "i", Port: "output"}},
+	}
+}
+
+func TestRegisterCompositesInAnyOrder(t *testing.T) {
+	factory := NewNodeFactory()
+	err := factory.RegisterComposites([]types.CompositeDefinition{
+		wrapper("aouter", "zinner"),
+		wrapper("zinner", "transform"),
+	})
+	if err != nil {
+		t.Fatalf("RegisterComposites failed: %v", err)
+	}
+
+	saved := factory.GetComposites()
+	names := make([]string, len(saved))
+	for i, definition := range saved {
+		names[i] = definition.Name
+	}
+	if strings.Join(names, ",") != "zinner,aouter" {
+		t.Errorf("GetComposites returned %v, want the inner composite first", names)
+	}
+
+	// Definitions as saved can be registered one by one
+	reloaded := NewNodeFactory()
+	for _, definition := range saved {
+		if err := reloaded.RegisterComposite(definition); err != nil {
+			t.Fatalf("reloading %s failed: %v", definition.Name, err)
+		}
+	}
+}
+
+func TestRegisterCompositeRejectsRecursion(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions []types.CompositeDefinition
+		want        string
+	}{
+		{
+			name:        "direct",
+			definitions: []types.CompositeDefinition{wrapper("a", "a")},
+			want:        "a -> a",
+		},
+		{
+			name:        "indirect",
+			definitions: []types.CompositeDefinition{wrapper("a", "b"), wrapper("b", "c"), wrapper("c", "a")},
+			want:        "a -> b -> c -> a",
+		},
+		{
+			name: "foreach body",
+			definitions: []types.CompositeDefinition{{
+				Name: "a",
+				Subgraph: types.Subgraph{Nodes: []types.NodeData{{
+					ID:   "loop",
+					Type: "foreach",
+					Config: map[string]interface{}{
+						"body": map[string]interface{}{
+							"nodes": []interface{}{map[string]interface{}{"id": "i", "type": "a"}},
+						},
+					},
+				}}},
+			}},
+			want: "a -> a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewNodeFactory().RegisterComposites(test.definitions)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestRegisterCompositeRejectsRecursionThroughRegistered(t *testing.T) {
+	factory := NewNodeFactory()
+	if err := factory.RegisterComposite(wrapper("b", "transform")); err != nil {
+		t.Fatal(err)
+	}
+	if err := factory.RegisterComposite(wrapper("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Redefining b in terms of a would make a contain itself
+	if err := factory.RegisterComposite(wrapper("b", "a")); err == nil {
+		t.Fatal("redefining b to contain a succeeded")
+	}
+	if definition, _ := factory.GetComposite("b"); definition.Nodes[0].Type != "transform" {
+		t.Errorf("failed registration replaced the definition of b")
+	}
+}
+
+// collapseGraph builds a -> b -> c, where a and c stay outside the
+// composite made from b.
+func collapseGraph(t *testing.T) *core.History {
+	graph := core.NewGraph()
+	for _, node := range []types.Node{NewTransformNode("a"), NewTransformNode("b"), NewTransformNode("c")} {
+		node.SetInputValue("operation", "to_string")
+		graph.AddNode(node)
+	}
+	graph.SetInputValue("a", "input", 7)
+	for _, conn := range []types.Connection{
+		{ID: "c1", SourceNode: "a", SourcePort: "output", TargetNode: "b", TargetPort: "input"},
+		{ID: "c2", SourceNode: "b", SourcePort: "output", TargetNode: "c", TargetPort: "input"},
+	} {
+		if err := graph.AddConnection(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return core.NewHistory(graph)
+}
+
+func TestCollapseToComposite(t *testing.T) {
+	factory := NewNodeFactory()
+	history := collapseGraph(t)
+	graph := history.Graph()
+
+	if _, err := factory.CollapseToComposite(history, "middle", "m", []string{"b"}, nil, nil); err != nil {
+		t.Fatalf("CollapseToComposite failed: %v", err)
+	}
+	if _, exists := graph.GetNode("b"); exists {
+		t.Error("collapsed node b is still in the graph")
+	}
+	if node, exists := graph.GetNode("m"); !exists || node.Type() != "middle" {
+		t.Fatalf("instance m of middle not found")
+	}
+
+	connections := graph.GetConnections()
+	if len(connections) != 2 || connections[0].TargetNode != "m" || connections[1].SourceNode != "m" {
+		t.Errorf("connections were not rewired to the instance: %+v", connections)
+	}
+
+	results, err := core.NewExecutor(graph).ExecuteGraph(context.Background())
+	if err != nil {
+		t.Fatalf("running the collapsed graph failed: %v", err)
+	}
+	for _, result := range results {
+		if result.NodeID == "c" && result.Outputs["output"] != "7" {
+			t.Errorf("c produced %v, want 7", result.Outputs["output"])
+		}
+	}
+
+	// The collapse is undone in one step
+	if err := history.Undo(); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if _, exists := graph.GetNode("m"); exists {
+		t.Error("instance m is still in the graph after undo")
+	}
+	if _, exists := graph.GetNode("b"); !exists {
+		t.Error("node b was not restored by undo")
+	}
+	if connections := graph.GetConnections(); len(connections) != 2 || connections[0].TargetNode != "b" || connections[1].SourceNode != "b" {
+		t.Errorf("connections were not restored by undo: %+v", connections)
+	}
+}
+
+func TestCollapseToCompositeFailureLeavesGraphUnchanged(t *testing.T) {
+	factory := NewNodeFactory()
+	history := collapseGraph(t)
+	graph := history.Graph()
+
+	inputs := []types.CompositePort{{Name: "in", Node: "b", Port: "missing"}}
+	if _, err := factory.CollapseToComposite(history, "middle", "m", []string{"b"}, inputs, nil); err == nil {
+		t.Fatal("collapse exposing a missing port succeeded")
+	}
+
+	if _, exists := graph.GetNode("b"); !exists {
+		t.Error("node b was removed by a failed collapse")
+	}
+	if len(graph.GetConnections()) != 2 {
+		t.Errorf("connections changed by a failed collapse: %+v", graph.GetConnections())
+	}
+	if _, exists := factory.GetComposite("middle"); exists {
+		t.Error("failed collapse registered the composite")
+	}
+	if history.CanUndo() {
+		t.Error("failed collapse was recorded in the history")
+	}
+}
+
+func TestCompositeBodyUsesRunVariables(t *testing.T) {
+	factory := NewNodeFactory()
+	err := factory.RegisterComposite(types.CompositeDefinition{
+		Name: "greeting",
+		Subgraph: types.Subgraph{
+			Nodes: []types.NodeData{{
+				ID:   "i",
+				Type: "transform",
+				Inputs: []types.NodeInput{
+					{Name: "input", Value: "{{greeting}}, {{name}}"},
+					{Name: "operation", Value: "to_string"},
+				},
+			}},
+		},
+		Outputs: []types.CompositePort{{Name: "text", Node: "i", Port: "output"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := factory.CreateNode("greeting", "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := core.NewGraph()
+	graph.AddNode(instance)
+	executor := core.NewExecutor(graph)
+	executor.SetVariables(map[string]interface{}{"greeting": "hello", "name": "world"})
+
+	results, err := executor.ExecuteGraph(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if text := results[0].Outputs["text"]; text != "hello, world" {
+		t.Errorf("composite produced %q, want the variables substituted", text)
+	}
+}
+
+func TestRedefiningCompositeInvalidatesCache(t *testing.T) {
+	factory := NewNodeFactory()
+	define := func(operation string) {
+		t.Helper()
+		definition := wrapper("convert", "transform")
+		definition.Nodes[0].Inputs = []types.NodeInput{{Name: "operation", Value: operation}}
+		if err := factory.RegisterComposite(definition); err != nil {
+			t.Fatal(err)
+		}
+	}
+	define("to_string")
+
+	instance, err := factory.CreateNode("convert", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance.SetInputValue("input", "7")
+	graph := core.NewGraph()
+	graph.AddNode(instance)
+	executor := core.NewExecutor(graph)
+	executor.SetCaching(true)
+
+	run := func() types.ExecutionResult {
+		t.Helper()
+		results, err := executor.ExecuteGraph(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results[0]
+	}
+	if result := run(); result.Outputs["output"] != "7" {
+		t.Fatalf("first run produced %#v", result.Outputs["output"])
+	}
+	if result := run(); !result.Cached {
+		t.Fatal("unchanged composite was not served from the cache")
+	}
+
+	define("to_int")
+	result := run()
+	if result.Cached || result.Outputs["output"] != 7 {
+		t.Errorf("after redefining: cached %v, output %#v; want a fresh run producing 7", result.Cached, result.Outputs["output"])
+	}
+}
@@ -31,6 +31,8 @@ func NewConditionalNode(id string) *ConditionalNode {
 			Outputs: []types.NodeOutput{
 				{Name: "result", Type: "bool", Description: "Condition result"},
 				{Name: "output", Type: "any", Description: "Selected output based on condition"},
+				{Name: "true", Type: "any", Description: "Branch taken when condition is true"},
+				{Name: "false", Type: "any", Description: "Branch taken when condition is false"},
 			},
 			Config: make(map[string]interface{}),
 		},
@@ -61,22 +63,27 @@ func (n *ConditionalNode) Execute(ctx context.Context, inputs map[string]interfa
 		output = falseOutput
 	}
 
-	// Update output values
-	for i := range n.Outputs {
-		switch n.Outputs[i].Name {
-		case "result":
-			n.Outputs[i].Value = result
-		case "output":
-			n.Outputs[i].Value = output
-		}
+	// The taken branch carries the selected output, or the evaluated value
+	branchValue := output
+	if branchValue == nil {
+		branchValue = value
+	}
+	branch := "false"
+	if result {
+		branch = "true"
 	}
 
 	return map[string]interface{}{
 		"result": result,
 		"output": output,
+		branch:   branchValue,
 	}, nil
 }
 
+func (n *ConditionalNode) BranchPorts() []string {
+	return []string{"true", "false"}
+}
+
 func (n *ConditionalNode) evaluateCondition(value interface{}, condition string, compareValue interface{}) (bool, error) {
 	switch condition {
 	case "exists":
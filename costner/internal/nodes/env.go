@@ -53,14 +53,6 @@ func (n *EnvNode) Execute(ctx context.Context, inputs map[string]interface{}) (m
 		}
 	}
 
-	// Update output value
-	for i := range n.Outputs {
-		if n.Outputs[i].Name == "variables" {
-			n.Outputs[i].Value = variables
-			break
-		}
-	}
-
 	return map[string]interface{}{
 		"variables": variables,
 	}, nil
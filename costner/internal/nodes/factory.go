@@ -2,14 +2,24 @@ package nodes
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"costner/pkg/types"
 )
 
-type NodeFactory struct{}
+var builtinNodeTypes = []string{"env", "request", "transform", "conditional", "variable", "foreach", "iteration", "poll"}
+
+type NodeFactory struct {
+	composites map[string]*compositeEntry
+	version    int
+	mutex      sync.RWMutex
+}
 
 func NewNodeFactory() *NodeFactory {
-	return &NodeFactory{}
+	return &NodeFactory{
+		composites: make(map[string]*compositeEntry),
+	}
 }
 
 func (f *NodeFactory) CreateNode(nodeType, id string) (types.Node, error) {
@@ -24,13 +34,39 @@ func (f *NodeFactory) CreateNode(nodeType, id string) (types.Node, error) {
 		return NewConditionalNode(id), nil
 	case "variable":
 		return NewVariableNode(id), nil
+	case "foreach":
+		node := NewForEachNode(id)
+		node.factory = f
+		return node, nil
+	case "iteration":
+		return NewIterationNode(id), nil
+	case "poll":
+		node := NewPollNode(id)
+		node.factory = f
+		return node, nil
 	default:
+		if _, _, exists := f.composite(nodeType); exists {
+			return newCompositeNode(f, nodeType, id), nil
+		}
 		return nil, fmt.Errorf("unknown node type: %s", nodeType)
 	}
 }
 
 func (f *NodeFactory) GetAvailableNodeTypes() []string {
-	return []string{"env", "request", "transform", "conditional", "variable"}
+	nodeTypes := append([]string{}, builtinNodeTypes...)
+	return append(nodeTypes, f.CompositeNames()...)
+}
+
+func (f *NodeFactory) CompositeNames() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	names := make([]string, 0, len(f.composites))
+	for name := range f.composites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (f *NodeFactory) CreateNodeFromData(data types.NodeData) (types.Node, error) {
@@ -40,6 +76,12 @@ func (f *NodeFactory) CreateNodeFromData(data types.NodeData) (types.Node, error
 	}
 
 	node.SetName(data.Name)
+	node.SetPosition(data.Position)
+
+	// Restore node configuration
+	for key, value := range data.Config {
+		node.SetConfigValue(key, value)
+	}
 
 	// Set input values
 	for _, input := range data.Inputs {
@@ -47,4 +89,4 @@ func (f *NodeFactory) CreateNodeFromData(data types.NodeData) (types.Node, error
 	}
 
 	return node, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,190 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// ForEachNode runs its body subgraph once for every element of the items
+// input. The body is stored in the "body" config key and receives the
+// current element through its "iteration" nodes. The output of the body node
+// named by "collect_node" (optionally narrowed to "collect_port") is
+// gathered from every iteration into the results list.
+type ForEachNode struct {
+	types.BaseNode
+	factory *NodeFactory
+}
+
+func NewForEachNode(id string) *ForEachNode {
+	node := &ForEachNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: "foreach",
+			NodeName: "For Each",
+			Inputs: []types.NodeInput{
+				{Name: "items", Type: "list", Required: true, Description: "Elements to iterate over"},
+				{Name: "context", Type: "any", Required: false, Description: "Value made available to every iteration"},
+				{Name: "parallelism", Type: "int", Required: false, Description: "Number of iterations run at once", Value: 1},
+			},
+			Outputs: []types.NodeOutput{
+				{Name: "results", Type: "list", Description: "Collected output of every iteration"},
+				{Name: "count", Type: "int", Description: "Number of iterations"},
+			},
+			Config: make(map[string]interface{}),
+		},
+	}
+	return node
+}
+
+func (n *ForEachNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	items, ok := inputs["items"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("items must be a list, got %T", inputs["items"])
+	}
+
+	parallelism := 1
+	if p, ok := inputs["parallelism"].(int); ok && p > 0 {
+		parallelism = p
+	}
+
+	body, err := subgraphFromConfig(n.Config, "body")
+	if err != nil {
+		return nil, err
+	}
+
+	collectNode, _ := n.Config["collect_node"].(string)
+	collectPort, _ := n.Config["collect_port"].(string)
+	if !subgraphHasNode(body, collectNode) {
+		return nil, fmt.Errorf("collect_node %q not found in body", collectNode)
+	}
+
+	// Cancel the remaining iterations as soon as one fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]interface{}, len(items))
+	indexes := make(chan int)
+	var firstErr error
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallelism && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				value, err := n.runIteration(ctx, body, i, items[i], inputs["context"], collectNode, collectPort)
+				if err != nil {
+					errMutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMutex.Unlock()
+					cancel()
+					continue
+				}
+				results[i] = value
+			}
+		}()
+	}
+
+	for i := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	result := map[string]interface{}{
+		"results": results,
+		"count":   len(items),
+	}
+
+	return result, nil
+}
+
+func (n *ForEachNode) runIteration(ctx context.Context, body types.Subgraph, index int, item, iterationContext interface{}, collectNode, collectPort string) (interface{}, error) {
+	factory := n.factory
+	if factory == nil {
+		factory = NewNodeFactory()
+	}
+
+	graph, err := factory.buildSubgraph(body)
+	if err != nil {
+		return nil, fmt.Errorf("iteration %d: %w", index, err)
+	}
+
+	// Feed the current element to every iteration node in the body
+	for _, nodeData := range body.Nodes {
+		if nodeData.Type != "iteration" {
+			continue
+		}
+		graph.SetInputValue(nodeData.ID, "item", item)
+		graph.SetInputValue(nodeData.ID, "index", index)
+		graph.SetInputValue(nodeData.ID, "context", iterationContext)
+	}
+
+	_, outputs, err := core.RunSubgraph(ctx, graph)
+	if err != nil {
+		return nil, fmt.Errorf("iteration %d: %w", index, err)
+	}
+
+	// A collect node skipped by a branch contributes nil
+	nodeOutputs, exists := outputs[collectNode]
+	if !exists {
+		return nil, nil
+	}
+	if collectPort == "" {
+		return nodeOutputs, nil
+	}
+	return nodeOutputs[collectPort], nil
+}
+
+// Version changes when a composite definition is edited, since the body may
+// contain composites.
+func (n *ForEachNode) Version() int {
+	return n.factory.compositesVersion()
+}
+
+func (n *ForEachNode) HasSideEffects() bool {
+	body, err := subgraphFromConfig(n.Config, "body")
+	if err != nil {
+		return false
+	}
+	factory := n.factory
+	if factory == nil {
+		factory = NewNodeFactory()
+	}
+	return factory.subgraphHasSideEffects(body)
+}
+
+func (n *ForEachNode) Clone() types.Node {
+	clone := NewForEachNode(n.NodeID)
+	clone.factory = n.factory
+	clone.NodeName = n.NodeName
+	clone.Position = n.Position
+	clone.Config = make(map[string]interface{})
+	for k, v := range n.Config {
+		clone.Config[k] = v
+	}
+	return clone
+}
+
+func (n *ForEachNode) Serialize() ([]byte, error) {
+	return json.Marshal(n.BaseNode)
+}
+
+func (n *ForEachNode) Deserialize(data []byte) error {
+	return json.Unmarshal(data, &n.BaseNode)
+}
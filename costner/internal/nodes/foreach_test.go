@@ -0,0 +1,76 @@
+package nodes
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"costner/pkg/types"
+)
+
+// newForEach returns a foreach node whose body converts each item with the
+// given transform operation.
+func newForEach(t *testing.T, operation string) types.Node {
+	t.Helper()
+	node, err := NewNodeFactory().CreateNodeFromData(types.NodeData{
+		ID:   "each",
+		Type: "foreach",
+		Config: map[string]interface{}{
+			"body": types.Subgraph{
+				Nodes: []types.NodeData{
+					{ID: "it", Type: "iteration"},
+					{ID: "convert", Type: "transform", Inputs: []types.NodeInput{{Name: "operation", Value: operation}}},
+				},
+				Connections: []types.Connection{
+					{ID: "c", SourceNode: "it", SourcePort: "item", TargetNode: "convert", TargetPort: "input"},
+				},
+			},
+			"collect_node": "convert",
+			"collect_port": "output",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestForEachCollectsInOrder(t *testing.T) {
+	for _, parallelism := range []int{1, 3} {
+		outputs, err := newForEach(t, "to_int").Execute(context.Background(), map[string]interface{}{
+			"items":       []interface{}{"1", "2", "3", "4"},
+			"parallelism": parallelism,
+		})
+		if err != nil {
+			t.Fatalf("parallelism %d: %v", parallelism, err)
+		}
+		if want := []interface{}{1, 2, 3, 4}; !reflect.DeepEqual(outputs["results"], want) {
+			t.Errorf("parallelism %d: results %v, want %v", parallelism, outputs["results"], want)
+		}
+		if outputs["count"] != 4 {
+			t.Errorf("parallelism %d: count %v, want 4", parallelism, outputs["count"])
+		}
+	}
+}
+
+func TestForEachFailsOnIterationError(t *testing.T) {
+	_, err := newForEach(t, "to_int").Execute(context.Background(), map[string]interface{}{
+		"items": []interface{}{"1", "x", "3"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "iteration 1") {
+		t.Errorf("got error %v, want iteration 1 to fail", err)
+	}
+}
+
+func TestForEachEmpty(t *testing.T) {
+	outputs, err := newForEach(t, "to_int").Execute(context.Background(), map[string]interface{}{
+		"items": []interface{}{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := outputs["results"].([]interface{}); len(results) != 0 || outputs["count"] != 0 {
+		t.Errorf("got %v, want no results", outputs)
+	}
+}
@@ -0,0 +1,65 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+
+	"costner/pkg/types"
+)
+
+// IterationNode is the entry point of a ForEach body. The ForEach node sets
+// its inputs for every element and the node passes them on to the body.
+type IterationNode struct {
+	types.BaseNode
+}
+
+func NewIterationNode(id string) *IterationNode {
+	node := &IterationNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: "iteration",
+			NodeName: "Iteration",
+			Inputs: []types.NodeInput{
+				{Name: "item", Type: "any", Required: false, Description: "Current element, set by the ForEach node"},
+				{Name: "index", Type: "int", Required: false, Description: "Index of the current element"},
+				{Name: "context", Type: "any", Required: false, Description: "Context value passed to the ForEach node"},
+			},
+			Outputs: []types.NodeOutput{
+				{Name: "item", Type: "any", Description: "Current element"},
+				{Name: "index", Type: "int", Description: "Index of the current element"},
+				{Name: "context", Type: "any", Description: "Context value passed to the ForEach node"},
+			},
+			Config: make(map[string]interface{}),
+		},
+	}
+	return node
+}
+
+func (n *IterationNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{
+		"item":    inputs["item"],
+		"index":   inputs["index"],
+		"context": inputs["context"],
+	}
+
+	return result, nil
+}
+
+func (n *IterationNode) Clone() types.Node {
+	clone := NewIterationNode(n.NodeID)
+	clone.NodeName = n.NodeName
+	clone.Position = n.Position
+	clone.Config = make(map[string]interface{})
+	for k, v := range n.Config {
+		clone.Config[k] = v
+	}
+	return clone
+}
+
+func (n *IterationNode) Serialize() ([]byte, error) {
+	return json.Marshal(n.BaseNode)
+}
+
+func (n *IterationNode) Deserialize(data []byte) error {
+	return json.Unmarshal(data, &n.BaseNode)
+}
@@ -0,0 +1,219 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// PollNode runs its body subgraph repeatedly until the output of the body
+// node named by "collect_node" (optionally narrowed to "collect_port")
+// satisfies the condition. The body is stored in the "body" config key like
+// a ForEach body; its iteration nodes receive the context input as item and
+// the zero-based attempt as index. Attempts that fail are retried as well.
+type PollNode struct {
+	types.BaseNode
+	factory *NodeFactory
+}
+
+// pollConditions are the conditions of a ConditionalNode that a PollNode
+// accepts.
+var pollConditions = map[string]bool{
+	"eq": true, "ne": true, "gt": true, "lt": true, "contains": true, "exists": true,
+}
+
+func NewPollNode(id string) *PollNode {
+	node := &PollNode{
+		BaseNode: types.BaseNode{
+			NodeID:   id,
+			NodeType: "poll",
+			NodeName: "Poll",
+			Inputs: []types.NodeInput{
+				{Name: "context", Type: "any", Required: false, Description: "Value made available to every attempt"},
+				{Name: "condition", Type: "string", Required: true, Description: "Condition the result must meet (eq, ne, gt, lt, contains, exists)"},
+				{Name: "compare_value", Type: "any", Required: false, Description: "Value to compare against"},
+				{Name: "interval", Type: "duration", Required: false, Description: "Wait between attempts", Value: "1s"},
+				{Name: "max_attempts", Type: "int", Required: false, Description: "Attempts before giving up (0 means no limit)", Value: 10},
+				{Name: "deadline", Type: "duration", Required: false, Description: "Give up after this long (0 means no deadline)"},
+			},
+			Outputs: []types.NodeOutput{
+				{Name: "result", Type: "any", Description: "Output of the attempt that met the condition"},
+				{Name: "attempts", Type: "int", Description: "Number of attempts made"},
+			},
+			Config: make(map[string]interface{}),
+		},
+	}
+	return node
+}
+
+func (n *PollNode) Execute(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	condition, ok := inputs["condition"].(string)
+	if !ok || condition == "" {
+		return nil, fmt.Errorf("condition is required")
+	}
+	if !pollConditions[condition] {
+		return nil, fmt.Errorf("unknown condition: %s", condition)
+	}
+	compareValue := inputs["compare_value"]
+
+	interval := time.Second
+	if d, ok := inputs["interval"].(time.Duration); ok && d >= 0 {
+		interval = d
+	}
+	maxAttempts := 10
+	if m, ok := inputs["max_attempts"].(int); ok && m >= 0 {
+		maxAttempts = m
+	}
+	if d, ok := inputs["deadline"].(time.Duration); ok && d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	body, err := subgraphFromConfig(n.Config, "body")
+	if err != nil {
+		return nil, err
+	}
+
+	collectNode, _ := n.Config["collect_node"].(string)
+	collectPort, _ := n.Config["collect_port"].(string)
+	if !subgraphHasNode(body, collectNode) {
+		return nil, fmt.Errorf("collect_node %q not found in body", collectNode)
+	}
+
+	var conditional ConditionalNode
+	var lastErr error
+	attempt := 0
+	for maxAttempts == 0 || attempt < maxAttempts {
+		if attempt > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, n.giveUp(attempt, lastErr, ctx.Err())
+			case <-timer.C:
+			}
+		}
+		attempt++
+
+		value, err := n.runAttempt(ctx, body, attempt, inputs["context"], collectNode, collectPort)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, n.giveUp(attempt, err, ctx.Err())
+			}
+			lastErr = err
+			continue
+		}
+
+		// A result the condition cannot be checked against, such as a
+		// non-numeric body under gt, does not meet it yet
+		met, err := conditional.evaluateCondition(value, condition, compareValue)
+		if err != nil {
+			lastErr = fmt.Errorf("condition evaluation failed on %v: %w", value, err)
+			continue
+		}
+		if met {
+			return map[string]interface{}{
+				"result":   value,
+				"attempts": attempt,
+			}, nil
+		}
+		lastErr = fmt.Errorf("got %v", value)
+	}
+
+	return nil, n.giveUp(attempt, lastErr, nil)
+}
+
+func (n *PollNode) giveUp(attempts int, lastErr, ctxErr error) error {
+	reason := fmt.Sprintf("condition not met after %d attempts", attempts)
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		reason = fmt.Sprintf("condition not met before the deadline after %d attempts", attempts)
+	} else if ctxErr != nil {
+		reason = fmt.Sprintf("polling cancelled after %d attempts", attempts)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%s, last attempt: %v", reason, lastErr)
+	}
+	if ctxErr != nil {
+		return fmt.Errorf("%s: %w", reason, ctxErr)
+	}
+	return fmt.Errorf("%s", reason)
+}
+
+func (n *PollNode) runAttempt(ctx context.Context, body types.Subgraph, attempt int, pollContext interface{}, collectNode, collectPort string) (interface{}, error) {
+	factory := n.factory
+	if factory == nil {
+		factory = NewNodeFactory()
+	}
+
+	graph, err := factory.buildSubgraph(body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeData := range body.Nodes {
+		if nodeData.Type != "iteration" {
+			continue
+		}
+		graph.SetInputValue(nodeData.ID, "item", pollContext)
+		graph.SetInputValue(nodeData.ID, "index", attempt-1)
+		graph.SetInputValue(nodeData.ID, "context", pollContext)
+	}
+
+	_, outputs, err := core.RunSubgraph(ctx, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeOutputs, exists := outputs[collectNode]
+	if !exists {
+		return nil, nil
+	}
+	if collectPort == "" {
+		return nodeOutputs, nil
+	}
+	return nodeOutputs[collectPort], nil
+}
+
+// Version changes when a composite definition is edited, since the body may
+// contain composites.
+func (n *PollNode) Version() int {
+	return n.factory.compositesVersion()
+}
+
+func (n *PollNode) HasSideEffects() bool {
+	body, err := subgraphFromConfig(n.Config, "body")
+	if err != nil {
+		return false
+	}
+	factory := n.factory
+	if factory == nil {
+		factory = NewNodeFactory()
+	}
+	return factory.subgraphHasSideEffects(body)
+}
+
+func (n *PollNode) Clone() types.Node {
+	clone := NewPollNode(n.NodeID)
+	clone.factory = n.factory
+	clone.NodeName = n.NodeName
+	clone.Position = n.Position
+	clone.Config = make(map[string]interface{})
+	for k, v := range n.Config {
+		clone.Config[k] = v
+	}
+	return clone
+}
+
+func (n *PollNode) Serialize() ([]byte, error) {
+	return json.Marshal(n.BaseNode)
+}
+
+func (n *PollNode) Deserialize(data []byte) error {
+	return json.Unmarshal(data, &n.BaseNode)
+}
@@ -0,0 +1,135 @@
+package nodes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"costner/pkg/types"
+)
+
+// pollServer answers the nth request with the nth body, repeating the last
+// one, and counts the requests.
+func pollServer(bodies ...string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(bodies) {
+			n = len(bodies)
+		}
+		io.WriteString(w, bodies[n-1])
+	}))
+	return server, &requests
+}
+
+// newPoll returns a poll node whose body requests url and checks the
+// response body.
+func newPoll(t *testing.T, url string, inputs map[string]interface{}) types.Node {
+	t.Helper()
+	data := types.NodeData{
+		ID:   "poll",
+		Type: "poll",
+		Config: map[string]interface{}{
+			"body": types.Subgraph{Nodes: []types.NodeData{{
+				ID:     "get",
+				Type:   "request",
+				Inputs: []types.NodeInput{{Name: "url", Value: url}},
+			}}},
+			"collect_node": "get",
+			"collect_port": "body",
+		},
+	}
+	for name, value := range inputs {
+		data.Inputs = append(data.Inputs, types.NodeInput{Name: name, Value: value})
+	}
+	node, err := NewNodeFactory().CreateNodeFromData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestPollUntilConditionMet(t *testing.T) {
+	// Non-numeric bodies do not meet gt yet, they are polled again
+	server, requests := pollServer("pending", "3", "7")
+	defer server.Close()
+
+	node := newPoll(t, server.URL, nil)
+	outputs, err := node.Execute(context.Background(), map[string]interface{}{
+		"condition":     "gt",
+		"compare_value": 5,
+		"interval":      time.Millisecond,
+		"max_attempts":  5,
+	})
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if outputs["result"] != "7" || outputs["attempts"] != 3 {
+		t.Errorf("got %v after %v attempts, want 7 after 3", outputs["result"], outputs["attempts"])
+	}
+	if *requests != 3 {
+		t.Errorf("sent %d requests, want 3", *requests)
+	}
+}
+
+func TestPollGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   map[string]interface{}
+		want     string
+		requests int32
+	}{
+		{
+			name:     "attempts exhausted",
+			inputs:   map[string]interface{}{"interval": time.Millisecond, "max_attempts": 3},
+			want:     "condition not met after 3 attempts, last attempt: got pending",
+			requests: 3,
+		},
+		{
+			name:   "deadline",
+			inputs: map[string]interface{}{"interval": 20 * time.Millisecond, "max_attempts": 0, "deadline": 50 * time.Millisecond},
+			want:   "condition not met before the deadline",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := pollServer("pending")
+			defer server.Close()
+
+			inputs := map[string]interface{}{"condition": "eq", "compare_value": "done"}
+			for name, value := range test.inputs {
+				inputs[name] = value
+			}
+			start := time.Now()
+			_, err := newPoll(t, server.URL, nil).Execute(context.Background(), inputs)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("got error %v, want %q", err, test.want)
+			}
+			if test.requests > 0 && *requests != test.requests {
+				t.Errorf("sent %d requests, want %d", *requests, test.requests)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("giving up took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestPollRejectsUnknownCondition(t *testing.T) {
+	server, requests := pollServer("done")
+	defer server.Close()
+
+	_, err := newPoll(t, server.URL, nil).Execute(context.Background(), map[string]interface{}{"condition": "matches"})
+	if err == nil || !strings.Contains(err.Error(), "unknown condition") {
+		t.Errorf("got error %v, want an unknown condition", err)
+	}
+	if *requests != 0 {
+		t.Errorf("sent %d requests for an unknown condition", *requests)
+	}
+}
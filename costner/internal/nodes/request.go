@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"costner/internal/core"
+	"costner/internal/httppool"
 	"costner/pkg/types"
 )
 
@@ -34,6 +36,7 @@ func NewRequestNode(id string) *RequestNode {
 				{Name: "headers", Type: "map", Description: "Response headers"},
 				{Name: "body", Type: "string", Description: "Response body"},
 				{Name: "duration", Type: "duration", Description: "Request duration"},
+				{Name: "wait", Type: "duration", Description: "Time spent waiting on host limits"},
 			},
 			Config: make(map[string]interface{}),
 		},
@@ -59,7 +62,8 @@ func (n *RequestNode) Execute(ctx context.Context, inputs map[string]interface{}
 
 	// Create HTTP client with timeout
 	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: core.Transport(ctx),
 	}
 
 	// Prepare request body
@@ -68,7 +72,8 @@ func (n *RequestNode) Execute(ctx context.Context, inputs map[string]interface{}
 		bodyReader = strings.NewReader(body)
 	}
 
-	// Create request
+	// Create request, tracking the time it waits on host limits
+	ctx, waited := httppool.TrackWait(ctx)
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -77,8 +82,17 @@ func (n *RequestNode) Execute(ctx context.Context, inputs map[string]interface{}
 	// Add headers
 	if headers, ok := inputs["headers"].(map[string]interface{}); ok {
 		for key, value := range headers {
-			if strValue, ok := value.(string); ok {
-				req.Header.Set(key, strValue)
+			switch v := value.(type) {
+			case nil:
+			case string:
+				req.Header.Set(key, v)
+			case []interface{}:
+				// Lists become repeated headers
+				for _, item := range v {
+					req.Header.Add(key, fmt.Sprintf("%v", item))
+				}
+			default:
+				req.Header.Set(key, fmt.Sprintf("%v", v))
 			}
 		}
 	}
@@ -86,7 +100,8 @@ func (n *RequestNode) Execute(ctx context.Context, inputs map[string]interface{}
 	// Execute request
 	start := time.Now()
 	resp, err := client.Do(req)
-	duration := time.Since(start)
+	wait := waited()
+	duration := time.Since(start) - wait
 
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -114,18 +129,16 @@ func (n *RequestNode) Execute(ctx context.Context, inputs map[string]interface{}
 		"headers":     responseHeaders,
 		"body":        string(bodyBytes),
 		"duration":    duration,
-	}
-
-	// Update output values
-	for i := range n.Outputs {
-		if value, exists := result[n.Outputs[i].Name]; exists {
-			n.Outputs[i].Value = value
-		}
+		"wait":        wait,
 	}
 
 	return result, nil
 }
 
+func (n *RequestNode) HasSideEffects() bool {
+	return true
+}
+
 func (n *RequestNode) Clone() types.Node {
 	clone := NewRequestNode(n.NodeID)
 	clone.NodeName = n.NodeName
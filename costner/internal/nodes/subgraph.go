@@ -0,0 +1,107 @@
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// subgraphFromConfig decodes a nested graph stored under key in a node config.
+func subgraphFromConfig(config map[string]interface{}, key string) (types.Subgraph, error) {
+	var subgraph types.Subgraph
+
+	raw, exists := config[key]
+	if !exists || raw == nil {
+		return subgraph, fmt.Errorf("%s is not configured", key)
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return subgraph, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, &subgraph); err != nil {
+		return subgraph, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return subgraph, nil
+}
+
+func subgraphHasNode(subgraph types.Subgraph, nodeID string) bool {
+	for _, nodeData := range subgraph.Nodes {
+		if nodeData.ID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// subgraphNodeTypes returns the types of the nodes of a nested graph,
+// including the nodes in the bodies of ForEach and Poll nodes.
+func subgraphNodeTypes(subgraph types.Subgraph) []string {
+	nodeTypes := make([]string, 0, len(subgraph.Nodes))
+	for _, nodeData := range subgraph.Nodes {
+		nodeTypes = append(nodeTypes, nodeData.Type)
+		if body, err := subgraphFromConfig(nodeData.Config, "body"); err == nil {
+			nodeTypes = append(nodeTypes, subgraphNodeTypes(body)...)
+		}
+	}
+	return nodeTypes
+}
+
+// subgraphHasSideEffects reports whether any node of a nested graph may
+// affect the outside world. Nodes that cannot be created count as having
+// side effects.
+func (f *NodeFactory) subgraphHasSideEffects(subgraph types.Subgraph) bool {
+	for _, nodeData := range subgraph.Nodes {
+		node, err := f.CreateNodeFromData(nodeData)
+		if err != nil || types.HasSideEffects(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSubgraph creates a fresh graph from a nested graph definition. Every
+// execution gets its own graph so concurrent runs never share nodes.
+func (f *NodeFactory) buildSubgraph(subgraph types.Subgraph) (*core.Graph, error) {
+	graph := core.NewGraph()
+
+	for _, nodeData := range subgraph.Nodes {
+		node, err := f.CreateNodeFromData(nodeData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create node %s: %w", nodeData.ID, err)
+		}
+		graph.AddNode(node)
+	}
+
+	for _, conn := range subgraph.Connections {
+		if err := graph.AddConnection(conn); err != nil {
+			return nil, fmt.Errorf("failed to add connection %s: %w", conn.ID, err)
+		}
+	}
+
+	return graph, nil
+}
+
+// nodeToData converts a node back into its project file representation.
+func nodeToData(node types.Node) types.NodeData {
+	nodeData := types.NodeData{
+		ID:      node.ID(),
+		Type:    node.Type(),
+		Name:    node.Name(),
+		Inputs:  node.GetInputs(),
+		Outputs: node.GetOutputs(),
+		Config:  node.GetConfig(),
+	}
+
+	// Get position from BaseNode if possible
+	if data, err := node.Serialize(); err == nil {
+		var baseNode types.BaseNode
+		if json.Unmarshal(data, &baseNode) == nil {
+			nodeData.Position = baseNode.Position
+		}
+	}
+
+	return nodeData
+}
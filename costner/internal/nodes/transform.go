@@ -67,14 +67,6 @@ func (n *TransformNode) Execute(ctx context.Context, inputs map[string]interface
 		return nil, fmt.Errorf("transform failed: %w", err)
 	}
 
-	// Update output value
-	for i := range n.Outputs {
-		if n.Outputs[i].Name == "output" {
-			n.Outputs[i].Value = result
-			break
-		}
-	}
-
 	return map[string]interface{}{
 		"output": result,
 	}, nil
@@ -85,6 +77,14 @@ func (n *TransformNode) jsonPath(input interface{}, path string) (interface{}, e
 		return input, nil
 	}
 
+	// Parse raw JSON text, such as a response body, before traversing it
+	if text, ok := input.(string); ok {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(text), &parsed); err == nil {
+			input = parsed
+		}
+	}
+
 	// Simple JSON path implementation
 	// For now, support simple dot notation like "field.subfield"
 	parts := strings.Split(path, ".")
@@ -27,6 +27,7 @@ func NewVariableNode(id string) *VariableNode {
 			},
 			Outputs: []types.NodeOutput{
 				{Name: "assignment", Type: "map", Description: "Variable assignment for request"},
+				{Name: "entry", Type: "map", Description: "Target key mapped to the value, for merging into request headers"},
 			},
 			Config: make(map[string]interface{}),
 		},
@@ -70,16 +71,9 @@ func (n *VariableNode) Execute(ctx context.Context, inputs map[string]interface{
 		"value": value,
 	}
 
-	// Update output value
-	for i := range n.Outputs {
-		if n.Outputs[i].Name == "assignment" {
-			n.Outputs[i].Value = assignment
-			break
-		}
-	}
-
 	return map[string]interface{}{
 		"assignment": assignment,
+		"entry":      map[string]interface{}{targetKey: value},
 	}, nil
 }
 
@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"costner/pkg/types"
+)
+
+// LoadDataset reads the rows of a dataset. Relative paths are resolved
+// against baseDir.
+func (p *ProjectPersistence) LoadDataset(source types.DatasetSource, baseDir string) ([]map[string]interface{}, error) {
+	path := source.Path
+	if path == "" {
+		return nil, fmt.Errorf("dataset path is required")
+	}
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	format := strings.ToLower(source.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var rows []map[string]interface{}
+	switch format {
+	case "csv":
+		rows, err = parseCSVDataset(data)
+	case "json":
+		err = json.Unmarshal(data, &rows)
+	case "ndjson", "jsonl":
+		rows, err = parseNDJSONDataset(data)
+	default:
+		return nil, fmt.Errorf("unsupported dataset format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dataset %s: %w", path, err)
+	}
+
+	return rows, nil
+}
+
+func parseCSVDataset(data []byte) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]interface{}, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseNDJSONDataset(data []byte) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
@@ -60,6 +60,11 @@ func (p *ProjectPersistence) LoadProject(filePath string) (*types.Project, error
 func (p *ProjectPersistence) ProjectToGraph(project *types.Project) (*core.Graph, error) {
 	graph := core.NewGraph()
 
+	// Register composite node types before creating their instances
+	if err := p.factory.RegisterComposites(project.Composites); err != nil {
+		return nil, err
+	}
+
 	// Create nodes
 	for _, nodeData := range project.Nodes {
 		node, err := p.factory.CreateNodeFromData(nodeData)
@@ -83,7 +88,7 @@ func (p *ProjectPersistence) GraphToProject(graph *core.Graph, name, description
 	nodes := make([]types.NodeData, 0)
 
 	// Convert nodes
-	for _, node := range graph.GetAllNodes() {
+	for _, node := range graph.GetNodesInOrder() {
 		nodeData := types.NodeData{
 			ID:       node.ID(),
 			Type:     node.Type(),
@@ -115,6 +120,7 @@ func (p *ProjectPersistence) GraphToProject(graph *core.Graph, name, description
 		Nodes:       nodes,
 		Connections: graph.GetConnections(),
 		Variables:   make(map[string]interface{}),
+		Composites:  p.factory.GetComposites(),
 	}
 }
 
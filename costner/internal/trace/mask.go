@@ -0,0 +1,196 @@
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// masker hides secrets in everything written to a trace. Values of inputs,
+// map keys, headers and variables with secret names are replaced with
+// core.SecretMask, and so are the values of secret variables wherever they
+// appear inside other strings, such as a token in a request URL.
+type masker struct {
+	replacer *strings.Replacer
+}
+
+// newMasker returns a masker for the values of the secret variables in
+// variables.
+func newMasker(variables ...map[string]interface{}) *masker {
+	var secrets []string
+	for _, vars := range variables {
+		for name, value := range vars {
+			if value == nil || !core.IsSecretName(name) {
+				continue
+			}
+			secret := fmt.Sprintf("%v", value)
+			if secret == "" || secret == core.SecretMask {
+				continue
+			}
+			secrets = append(secrets, secret, url.QueryEscape(secret), url.PathEscape(secret))
+		}
+	}
+
+	// Longer secrets go first, so that a secret containing another one is
+	// masked as a whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, core.SecretMask)
+	}
+	return &masker{replacer: strings.NewReplacer(pairs...)}
+}
+
+func (m *masker) string(s string) string {
+	return m.replacer.Replace(s)
+}
+
+// value masks name's value, and the secrets in any strings, map entries and
+// list items it contains.
+func (m *masker) value(name string, value interface{}) interface{} {
+	switch v := core.MaskSecrets(name, value).(type) {
+	case string:
+		return m.text(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = m.value(key, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = m.value("", item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func (m *masker) values(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	masked := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		masked[name] = m.value(name, value)
+	}
+	return masked
+}
+
+// text masks a string such as a request body. Strings holding JSON
+// additionally have the values of secret keys masked; they are only
+// re-encoded when that changes anything.
+func (m *masker) text(text string) string {
+	text = m.string(text)
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return text
+	}
+	masked := m.value("", decoded)
+	if reflect.DeepEqual(masked, decoded) {
+		return text
+	}
+	data, err := json.Marshal(masked)
+	if err != nil {
+		return text
+	}
+	return string(data)
+}
+
+func (m *masker) headers(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	masked := make(http.Header, len(header))
+	for name, values := range header {
+		if core.IsSecretName(name) {
+			masked[name] = []string{core.SecretMask}
+			continue
+		}
+		masked[name] = make([]string, len(values))
+		for i, value := range values {
+			masked[name][i] = m.string(value)
+		}
+	}
+	return masked
+}
+
+func (m *masker) exchange(exchange types.HTTPExchange) types.HTTPExchange {
+	exchange.URL = m.string(exchange.URL)
+	exchange.RequestHeaders = m.headers(exchange.RequestHeaders)
+	exchange.RequestBody = m.text(exchange.RequestBody)
+	exchange.ResponseHeaders = m.headers(exchange.ResponseHeaders)
+	exchange.ResponseBody = m.text(exchange.ResponseBody)
+	exchange.Error = m.string(exchange.Error)
+	return exchange
+}
+
+func (m *masker) result(result types.ExecutionResult) types.ExecutionResult {
+	result.Inputs = m.values(result.Inputs)
+	result.Outputs = m.values(result.Outputs)
+	result.Error = m.string(result.Error)
+	return result
+}
+
+func (m *masker) nodes(nodes []types.NodeData) []types.NodeData {
+	masked := make([]types.NodeData, len(nodes))
+	for i, node := range nodes {
+		node.Config = m.values(node.Config)
+		inputs := make([]types.NodeInput, len(node.Inputs))
+		for j, input := range node.Inputs {
+			input.Value = m.value(input.Name, input.Value)
+			inputs[j] = input
+		}
+		node.Inputs = inputs
+		masked[i] = node
+	}
+	return masked
+}
+
+// project returns a copy of project with its variables and the static
+// input values and config of its nodes masked.
+func (m *masker) project(project *types.Project) *types.Project {
+	if project == nil {
+		return nil
+	}
+	masked := *project
+	masked.Variables = m.values(project.Variables)
+	masked.Nodes = m.nodes(project.Nodes)
+	masked.Composites = make([]types.CompositeDefinition, len(project.Composites))
+	for i, definition := range project.Composites {
+		definition.Nodes = m.nodes(definition.Nodes)
+		masked.Composites[i] = definition
+	}
+	return &masked
+}
+
+// ReplayVariables returns the variables to replay run with. Recorded
+// secrets are masked, so each masked variable takes the value of the
+// environment variable with the same name, or its upper case name, when
+// one is set. Requests built from secrets that stay masked still match the
+// recording, because the recorded requests are masked the same way.
+func ReplayVariables(run types.TraceRun) map[string]interface{} {
+	variables := make(map[string]interface{}, len(run.Variables))
+	for name, value := range run.Variables {
+		if value == core.SecretMask {
+			if env, ok := os.LookupEnv(name); ok {
+				value = env
+			} else if env, ok := os.LookupEnv(strings.ToUpper(name)); ok {
+				value = env
+			}
+		}
+		variables[name] = value
+	}
+	return variables
+}
@@ -0,0 +1,93 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// Recorder is an http.RoundTripper that records every exchange it forwards
+// to the underlying transport. Headers that look like secrets are masked in
+// the recording.
+type Recorder struct {
+	transport http.RoundTripper
+	exchanges []types.HTTPExchange
+	mutex     sync.Mutex
+}
+
+// NewRecorder wraps transport, or http.DefaultTransport when it is nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := types.HTTPExchange{
+		NodeID:         core.NodeID(req.Context()),
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeaders: maskHeaders(req.Header),
+		Timestamp:      time.Now(),
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		exchange.RequestBody = string(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		exchange.Error = err.Error()
+		exchange.Duration = time.Since(exchange.Timestamp)
+		r.add(exchange)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	exchange.Duration = time.Since(exchange.Timestamp)
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeaders = maskHeaders(resp.Header)
+	exchange.ResponseBody = string(body)
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	r.add(exchange)
+	return resp, err
+}
+
+func (r *Recorder) add(exchange types.HTTPExchange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// Exchanges returns the recorded exchanges in the order they were sent.
+func (r *Recorder) Exchanges() []types.HTTPExchange {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]types.HTTPExchange(nil), r.exchanges...)
+}
+
+func maskHeaders(header http.Header) http.Header {
+	masked := header.Clone()
+	for name := range masked {
+		if core.IsSecretName(name) {
+			masked[name] = []string{core.SecretMask}
+		}
+	}
+	return masked
+}
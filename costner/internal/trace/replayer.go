@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"costner/pkg/types"
+)
+
+// Replayer is an http.RoundTripper that answers requests with recorded
+// responses instead of using the network. A request is matched by method,
+// URL and body, or by method and URL alone if no recorded request has the
+// same body. Repeated requests receive the recorded responses in order;
+// once those run out the last one is repeated.
+//
+// Recorded requests have their secrets masked, so requests are masked the
+// same way before matching, using the secret variables the replay runs
+// with.
+type Replayer struct {
+	exact  map[string][]types.HTTPExchange
+	loose  map[string][]types.HTTPExchange
+	served map[string]int
+	masker *masker
+	mutex  sync.Mutex
+}
+
+func NewReplayer(exchanges []types.HTTPExchange, variables map[string]interface{}) *Replayer {
+	r := &Replayer{
+		exact:  make(map[string][]types.HTTPExchange),
+		loose:  make(map[string][]types.HTTPExchange),
+		served: make(map[string]int),
+		masker: newMasker(variables),
+	}
+	for _, exchange := range exchanges {
+		exactKey := exchangeKey(exchange.Method, exchange.URL, exchange.RequestBody)
+		looseKey := exchangeKey(exchange.Method, exchange.URL, "")
+		r.exact[exactKey] = append(r.exact[exactKey], exchange)
+		r.loose[looseKey] = append(r.loose[looseKey], exchange)
+	}
+	return r
+}
+
+func exchangeKey(method, url, body string) string {
+	return strings.Join([]string{method, url, body}, "\x00")
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+
+	exchange, ok := r.next(req.Method, r.masker.string(req.URL.String()), r.masker.text(body))
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+	if exchange.StatusCode == 0 {
+		return nil, fmt.Errorf("recorded error: %s", exchange.Error)
+	}
+
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(exchange.ResponseBody))),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) next(method, url, body string) (types.HTTPExchange, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	exactKey := exchangeKey(method, url, body)
+	if exchanges := r.exact[exactKey]; len(exchanges) > 0 {
+		return r.take("exact"+exactKey, exchanges), true
+	}
+	looseKey := exchangeKey(method, url, "")
+	if exchanges := r.loose[looseKey]; len(exchanges) > 0 {
+		return r.take("loose"+looseKey, exchanges), true
+	}
+	return types.HTTPExchange{}, false
+}
+
+func (r *Replayer) take(key string, exchanges []types.HTTPExchange) types.HTTPExchange {
+	i := r.served[key]
+	if i >= len(exchanges) {
+		i = len(exchanges) - 1
+	}
+	r.served[key]++
+	return exchanges[i]
+}
@@ -0,0 +1,112 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"costner/internal/core"
+	"costner/pkg/types"
+)
+
+// Recording collects the runs of a project into a trace. Secrets are
+// masked in everything the trace stores: the project, the run variables,
+// node inputs and outputs and the HTTP exchanges.
+type Recording struct {
+	trace     types.Trace
+	variables map[string]interface{}
+	mutex     sync.Mutex
+}
+
+func NewRecording(project *types.Project, target string, options types.TraceOptions) *Recording {
+	return &Recording{
+		trace: types.Trace{
+			Version: types.TraceVersion,
+			Project: newMasker(project.Variables).project(project),
+			Target:  target,
+			Options: options,
+		},
+		variables: project.Variables,
+	}
+}
+
+// Record calls run with a context that records every HTTP exchange and adds
+// the run, with its node results, to the trace.
+func (r *Recording) Record(ctx context.Context, variables map[string]interface{}, run func(context.Context) ([]types.ExecutionResult, error)) ([]types.ExecutionResult, error) {
+	recorder := NewRecorder(nil)
+	start := time.Now()
+	results, err := run(core.WithTransport(ctx, recorder))
+
+	masker := newMasker(r.variables, variables)
+	exchanges := recorder.Exchanges()
+	traceRun := types.TraceRun{
+		Variables: masker.values(variables),
+		Start:     start,
+		Duration:  time.Since(start),
+		Nodes:     make([]types.ExecutionResult, len(results)),
+		Exchanges: make([]types.HTTPExchange, len(exchanges)),
+	}
+	if err != nil {
+		traceRun.Error = masker.string(err.Error())
+	}
+	for i, result := range results {
+		traceRun.Nodes[i] = masker.result(result)
+	}
+	for i, exchange := range exchanges {
+		traceRun.Exchanges[i] = masker.exchange(exchange)
+	}
+
+	r.mutex.Lock()
+	r.trace.Runs = append(r.trace.Runs, traceRun)
+	r.mutex.Unlock()
+
+	return results, err
+}
+
+func (r *Recording) Trace() *types.Trace {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	trace := r.trace
+	trace.Runs = append([]types.TraceRun(nil), r.trace.Runs...)
+	return &trace
+}
+
+// Save writes a trace as indented JSON.
+func Save(trace *types.Trace, filePath string) error {
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return nil
+}
+
+// Load reads a trace file.
+func Load(filePath string) (*types.Trace, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+func Read(reader io.Reader) (*types.Trace, error) {
+	var trace types.Trace
+	if err := json.NewDecoder(reader).Decode(&trace); err != nil {
+		return nil, fmt.Errorf("failed to parse trace: %w", err)
+	}
+	if trace.Version != types.TraceVersion {
+		return nil, fmt.Errorf("unsupported trace version %d", trace.Version)
+	}
+	if trace.Project == nil {
+		return nil, fmt.Errorf("trace contains no project")
+	}
+	return &trace, nil
+}
@@ -0,0 +1,229 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"costner/internal/core"
+	"costner/internal/nodes"
+	"costner/pkg/types"
+)
+
+func TestRecordMasksSecrets(t *testing.T) {
+	recording := NewRecording(&types.Project{Name: "test"}, "", types.TraceOptions{KeepGoing: true, Concurrency: 2})
+
+	variables := map[string]interface{}{"user": "alice", "password": "hunter2", "api_token": "abc"}
+	_, err := recording.Record(context.Background(), variables, func(ctx context.Context) ([]types.ExecutionResult, error) {
+		return []types.ExecutionResult{{
+			NodeID:  "login",
+			Inputs:  map[string]interface{}{"headers": map[string]interface{}{"Authorization": "Bearer abc"}},
+			Outputs: map[string]interface{}{"token": "xyz", "status": 200},
+		}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Record returned %v", err)
+	}
+
+	recorded := recording.Trace()
+	if !recorded.Options.KeepGoing || recorded.Options.Concurrency != 2 {
+		t.Errorf("options = %+v, want keep-going with concurrency 2", recorded.Options)
+	}
+
+	run := recorded.Runs[0]
+	if run.Variables["user"] != "alice" {
+		t.Errorf("variable user = %v, want alice", run.Variables["user"])
+	}
+	for _, name := range []string{"password", "api_token"} {
+		if run.Variables[name] != core.SecretMask {
+			t.Errorf("variable %s = %v, want it masked", name, run.Variables[name])
+		}
+	}
+	if variables["password"] != "hunter2" {
+		t.Errorf("Record modified the caller's variables")
+	}
+
+	node := run.Nodes[0]
+	headers := node.Inputs["headers"].(map[string]interface{})
+	if headers["Authorization"] != core.SecretMask {
+		t.Errorf("authorization header = %v, want it masked", headers["Authorization"])
+	}
+	if node.Outputs["token"] != core.SecretMask || node.Outputs["status"] != 200 {
+		t.Errorf("outputs = %v, want token masked and status kept", node.Outputs)
+	}
+}
+
+func TestRecorderMasksSecretHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=1")
+		io.WriteString(w, "pong")
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(nil)
+	client := &http.Client{Transport: recorder}
+	req, _ := http.NewRequest("POST", server.URL+"/ping", strings.NewReader("ping"))
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("Accept", "text/plain")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("caller got body %q, want pong", body)
+	}
+
+	exchanges := recorder.Exchanges()
+	if len(exchanges) != 1 {
+		t.Fatalf("recorded %d exchanges, want 1", len(exchanges))
+	}
+	exchange := exchanges[0]
+	if exchange.RequestBody != "ping" || exchange.ResponseBody != "pong" || exchange.StatusCode != 200 {
+		t.Errorf("exchange = %+v, want ping/pong with status 200", exchange)
+	}
+	if got := exchange.RequestHeaders.Get("Authorization"); got != core.SecretMask {
+		t.Errorf("authorization header recorded as %q", got)
+	}
+	if got := exchange.RequestHeaders.Get("Accept"); got != "text/plain" {
+		t.Errorf("accept header recorded as %q", got)
+	}
+	if got := exchange.ResponseHeaders.Get("Set-Cookie"); got != core.SecretMask {
+		t.Errorf("cookie header recorded as %q", got)
+	}
+}
+
+func TestReplayerMatching(t *testing.T) {
+	exchanges := []types.HTTPExchange{
+		{Method: "GET", URL: "http://api/job", StatusCode: 200, ResponseBody: "pending"},
+		{Method: "GET", URL: "http://api/job", StatusCode: 200, ResponseBody: "done"},
+		{Method: "POST", URL: "http://api/items", RequestBody: `{"a":1}`, StatusCode: 201, ResponseBody: "one"},
+		{Method: "POST", URL: "http://api/items", RequestBody: `{"a":2}`, StatusCode: 201, ResponseBody: "two"},
+		{Method: "GET", URL: "http://api/down", Error: "connection refused"},
+	}
+
+	tests := []struct {
+		method, url, body string
+		want              string
+		wantErr           bool
+	}{
+		{method: "GET", url: "http://api/job", want: "pending"},
+		{method: "GET", url: "http://api/job", want: "done"},
+		{method: "GET", url: "http://api/job", want: "done"},
+		{method: "POST", url: "http://api/items", body: `{"a":2}`, want: "two"},
+		{method: "POST", url: "http://api/items", body: `{"a":3}`, want: "one"},
+		{method: "GET", url: "http://api/down", wantErr: true},
+		{method: "GET", url: "http://api/other", wantErr: true},
+	}
+
+	replayer := NewReplayer(exchanges, nil)
+	for i, test := range tests {
+		req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+		resp, err := replayer.RoundTrip(req)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%d: %s %s succeeded, want an error", i, test.method, test.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %s %s failed: %v", i, test.method, test.url, err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != test.want {
+			t.Errorf("%d: %s %s got %q, want %q", i, test.method, test.url, body, test.want)
+		}
+	}
+}
+
+func TestRecordReplayRoundTripWithSecret(t *testing.T) {
+	const secret = "s3cr3t-value"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("t") != secret {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		io.WriteString(w, `{"item":"widget","echo":"`+secret+`"}`)
+	}))
+	defer server.Close()
+
+	project := &types.Project{
+		Name: "secret",
+		Nodes: []types.NodeData{{
+			ID:   "get",
+			Type: "request",
+			Inputs: []types.NodeInput{
+				{Name: "url", Value: "{{base}}/item?t={{api_token}}"},
+				{Name: "body", Value: `{"password":"hunter2"}`},
+			},
+		}},
+		Variables: map[string]interface{}{"base": server.URL, "api_token": secret},
+	}
+	graph := func() *core.Graph {
+		factory := nodes.NewNodeFactory()
+		graph := core.NewGraph()
+		for _, data := range project.Nodes {
+			node, err := factory.CreateNodeFromData(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			graph.AddNode(node)
+		}
+		return graph
+	}
+
+	executor := core.NewExecutor(graph())
+	executor.SetVariables(project.Variables)
+	recording := NewRecording(project, "", types.TraceOptions{})
+	results, err := recording.Record(context.Background(), executor.Variables(), executor.ExecuteGraph)
+	if err != nil || !results[0].Success {
+		t.Fatalf("recorded run failed: %v %+v", err, results)
+	}
+
+	var saved strings.Builder
+	if err := json.NewEncoder(&saved).Encode(recording.Trace()); err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{secret, "hunter2"} {
+		if strings.Contains(saved.String(), leaked) {
+			t.Errorf("trace contains the secret %q:\n%s", leaked, saved.String())
+		}
+	}
+	recorded, err := Read(strings.NewReader(saved.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay := func(t *testing.T) {
+		run := recorded.Runs[0]
+		variables := ReplayVariables(run)
+		executor := core.NewExecutor(graph())
+		executor.SetVariables(variables)
+		ctx := core.WithTransport(context.Background(), NewReplayer(run.Exchanges, variables))
+		results, err := executor.ExecuteGraph(ctx)
+		if err != nil {
+			t.Fatalf("replay failed: %v", err)
+		}
+		if results[0].Outputs["status_code"] != 200 {
+			t.Errorf("replayed status %v, want 200", results[0].Outputs["status_code"])
+		}
+	}
+
+	before := requests
+	t.Run("masked", replay)
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("API_TOKEN", secret)
+		replay(t)
+	})
+	if requests != before {
+		t.Errorf("replay sent %d requests to the server", requests-before)
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"costner/internal/core"
 	"costner/internal/nodes"
+	"costner/internal/trace"
 	"costner/pkg/types"
 )
 
@@ -20,9 +22,23 @@ type Canvas struct {
 	content      *fyne.Container
 	background   *canvas.Rectangle
 	graph        *core.Graph
+	executor     *core.Executor
+	history      *core.History
 	factory      *nodes.NodeFactory
 	nodeWidgets  map[string]*NodeWidget
+	selected     map[string]bool
+	connections  *ConnectionManager
 	nextPosition fyne.Position
+
+	// Runs in progress, cancelled by the Stop button
+	runs      map[int]context.CancelFunc
+	nextRunID int
+
+	// Connection currently being dragged out of an output port
+	dragSource   *PortWidget
+	dragLine     *canvas.Line
+	dragPosition fyne.Position
+	nextConnID   int
 }
 
 func NewCanvas() *Canvas {
@@ -30,8 +46,16 @@ func NewCanvas() *Canvas {
 		graph:        core.NewGraph(),
 		factory:      nodes.NewNodeFactory(),
 		nodeWidgets:  make(map[string]*NodeWidget),
+		selected:     make(map[string]bool),
+		runs:         make(map[int]context.CancelFunc),
 		nextPosition: fyne.NewPos(50, 50),
 	}
+	c.history = core.NewHistory(c.graph)
+	c.executor = core.NewExecutor(c.graph)
+	// Re-running the canvas only executes the nodes that changed
+	c.executor.SetCaching(true)
+	c.executor.Observe(c.handleExecutionEvent)
+	c.graph.Subscribe(c.handleGraphChange)
 
 	// Create background
 	c.background = canvas.NewRectangle(theme.BackgroundColor())
@@ -39,6 +63,7 @@ func NewCanvas() *Canvas {
 
 	c.content = container.NewWithoutLayout()
 	c.content.Add(c.background) // Add background first
+	c.connections = NewConnectionManager(c.content)
 
 	// Create toolbar
 	toolbar := c.createToolbar()
@@ -60,10 +85,30 @@ func (c *Canvas) createToolbar() *fyne.Container {
 		c.showAddNodeDialog()
 	})
 
+	undoBtn := widget.NewButton("Undo", func() {
+		c.undo()
+	})
+
+	redoBtn := widget.NewButton("Redo", func() {
+		c.redo()
+	})
+
+	compositeBtn := widget.NewButton("Make Composite", func() {
+		c.showCompositeDialog()
+	})
+
 	runBtn := widget.NewButton("Run", func() {
 		c.executeGraph()
 	})
 
+	stopBtn := widget.NewButton("Stop", func() {
+		c.stopRun()
+	})
+
+	clearBtn := widget.NewButton("Clear Cache", func() {
+		c.executor.ClearResults()
+	})
+
 	saveBtn := widget.NewButton("Save", func() {
 		c.saveProject()
 	})
@@ -72,7 +117,11 @@ func (c *Canvas) createToolbar() *fyne.Container {
 		c.loadProject()
 	})
 
-	return container.NewHBox(addBtn, runBtn, saveBtn, loadBtn)
+	traceBtn := widget.NewButton("Open Trace", func() {
+		c.openTrace()
+	})
+
+	return container.NewHBox(addBtn, undoBtn, redoBtn, compositeBtn, runBtn, stopBtn, clearBtn, saveBtn, loadBtn, traceBtn)
 }
 
 func (c *Canvas) showAddNodeDialog() {
@@ -103,7 +152,12 @@ func (c *Canvas) showAddNodeDialog() {
 		}
 
 		node.SetName(nameEntry.Text)
-		c.addNodeToCanvas(node, c.getNextPosition())
+		pos := c.getNextPosition()
+		node.SetPosition(types.Position{X: pos.X, Y: pos.Y})
+		if err := c.history.AddNode(node); err != nil {
+			c.showError("Error", err.Error())
+			return
+		}
 		dialog.Hide()
 	})
 
@@ -118,28 +172,351 @@ func (c *Canvas) showAddNodeDialog() {
 	dialog.Show()
 }
 
-func (c *Canvas) addNodeToCanvas(node types.Node, position fyne.Position) {
-	widget := NewNodeWidget(node, position)
+func (c *Canvas) addNodeWidget(node types.Node) {
+	pos := node.GetPosition()
+	widget := NewNodeWidget(node, fyne.NewPos(pos.X, pos.Y))
 	widget.SetCallbacks(
 		func(nodeID string) { c.executeNode(nodeID) },
-		func(nodeID string, pos fyne.Position) { /* handle move */ },
+		func(nodeID string, pos fyne.Position) { c.updateConnections(nodeID) },
+		func(nodeID, name string, value interface{}) { c.history.SetInputValue(nodeID, name, value) },
 	)
+	widget.SetDropCallback(func(nodeID string, pos fyne.Position) {
+		c.history.MoveNode(nodeID, types.Position{X: pos.X, Y: pos.Y})
+	})
+	widget.SetSelectCallback(c.toggleSelection)
+	widget.SetPortCallbacks(c.dragConnection, c.endConnectionDrag)
 	c.nodeWidgets[node.ID()] = widget
-	c.graph.AddNode(node)
 	c.content.Add(widget.Container())
 	c.content.Refresh()
 }
 
-func (c *Canvas) executeGraph() {
-	executor := core.NewExecutor(c.graph)
-	results, err := executor.ExecuteGraph(context.Background())
+// toggleSelection adds a node to the selection, or removes it if it is
+// already selected.
+func (c *Canvas) toggleSelection(nodeID string) {
+	widget, exists := c.nodeWidgets[nodeID]
+	if !exists {
+		return
+	}
+	if c.selected[nodeID] {
+		delete(c.selected, nodeID)
+	} else {
+		c.selected[nodeID] = true
+	}
+	widget.SetSelected(c.selected[nodeID])
+}
+
+func (c *Canvas) clearSelection() {
+	for nodeID := range c.selected {
+		if widget, exists := c.nodeWidgets[nodeID]; exists {
+			widget.SetSelected(false)
+		}
+	}
+	c.selected = make(map[string]bool)
+}
+
+// showCompositeDialog asks for a name and collapses the selected nodes into
+// an instance of a new composite type.
+func (c *Canvas) showCompositeDialog() {
+	if len(c.selected) == 0 {
+		c.showError("Make Composite", "Select nodes with Ctrl-click or Shift-click first")
+		return
+	}
+
+	nameEntry := widget.NewEntry()
+	content := container.NewVBox(
+		widget.NewLabel("Composite type name:"),
+		nameEntry,
+	)
+
+	dialog := widget.NewModalPopUp(content, fyne.CurrentApp().Driver().AllWindows()[0].Canvas())
+
+	createBtn := widget.NewButton("Create", func() {
+		if nameEntry.Text == "" {
+			return
+		}
+		if err := c.collapseSelection(nameEntry.Text); err != nil {
+			c.showError("Make Composite", err.Error())
+			return
+		}
+		dialog.Hide()
+	})
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		dialog.Hide()
+	})
+
+	content.Add(container.NewHBox(createBtn, cancelBtn))
+	dialog.Resize(fyne.NewSize(300, 150))
+	dialog.Show()
+}
+
+// collapseSelection replaces the selected nodes with an instance of a new
+// composite type. The collapse is a single undo step.
+func (c *Canvas) collapseSelection(name string) error {
+	nodeIDs := make([]string, 0, len(c.selected))
+	for _, node := range c.graph.GetNodesInOrder() {
+		if c.selected[node.ID()] {
+			nodeIDs = append(nodeIDs, node.ID())
+		}
+	}
+
+	instanceID := fmt.Sprintf("node_%d", len(c.nodeWidgets)+1)
+	for i := len(c.nodeWidgets) + 2; ; i++ {
+		if _, exists := c.graph.GetNode(instanceID); !exists {
+			break
+		}
+		instanceID = fmt.Sprintf("node_%d", i)
+	}
+
+	if _, err := c.factory.CollapseToComposite(c.history, name, instanceID, nodeIDs, nil, nil); err != nil {
+		return err
+	}
+	c.clearSelection()
+	return nil
+}
+
+func (c *Canvas) undo() {
+	if !c.history.CanUndo() {
+		return
+	}
+	if err := c.history.Undo(); err != nil {
+		c.showError("Undo Error", err.Error())
+	}
+}
+
+func (c *Canvas) redo() {
+	if !c.history.CanRedo() {
+		return
+	}
+	if err := c.history.Redo(); err != nil {
+		c.showError("Redo Error", err.Error())
+	}
+}
+
+// handleGraphChange keeps the widgets in sync with the graph, whichever
+// front end changed it.
+func (c *Canvas) handleGraphChange(change types.GraphChange) {
+	fyne.Do(func() {
+		switch change.Type {
+		case types.ChangeNodeAdded:
+			if _, exists := c.nodeWidgets[change.NodeID]; !exists {
+				c.addNodeWidget(change.Node)
+			}
+		case types.ChangeNodeRemoved:
+			if widget, exists := c.nodeWidgets[change.NodeID]; exists {
+				c.content.Remove(widget.Container())
+				delete(c.nodeWidgets, change.NodeID)
+				delete(c.selected, change.NodeID)
+				c.content.Refresh()
+			}
+		case types.ChangeNodeUpdated:
+			if widget, exists := c.nodeWidgets[change.NodeID]; exists {
+				widget.Sync()
+				c.updateConnections(change.NodeID)
+			}
+		case types.ChangeConnectionAdded:
+			c.addConnectionWidget(*change.Connection)
+		case types.ChangeConnectionRemoved:
+			c.connections.RemoveConnection(change.Connection.ID)
+		}
+	})
+}
+
+func (c *Canvas) addConnectionWidget(conn types.Connection) {
+	sourceWidget, sourceExists := c.nodeWidgets[conn.SourceNode]
+	targetWidget, targetExists := c.nodeWidgets[conn.TargetNode]
+	if !sourceExists || !targetExists {
+		return
+	}
+	source := sourceWidget.OutputPort(conn.SourcePort)
+	target := targetWidget.InputPort(conn.TargetPort)
+	if source == nil || target == nil {
+		return
+	}
+	c.connections.AddConnection(conn, c.portCenter(source), c.portCenter(target))
+}
+
+// dragConnection draws a connection from an output port to the pointer and
+// highlights the input ports it can be connected to.
+func (c *Canvas) dragConnection(port *PortWidget, event *fyne.DragEvent) {
+	if c.dragSource == nil {
+		if port.isInput {
+			return
+		}
+		c.dragSource = port
+		start := c.portCenter(port)
+		c.dragLine = canvas.NewLine(theme.PrimaryColor())
+		c.dragLine.StrokeWidth = 2
+		c.dragLine.Position1 = start
+		c.dragLine.Position2 = start
+		c.content.Add(c.dragLine)
+		c.highlightPorts(port)
+	}
+
+	c.dragPosition = event.AbsolutePosition
+	c.dragLine.Position2 = c.dragPosition.Subtract(c.absolutePosition(c.content))
+	c.dragLine.Refresh()
+}
+
+// endConnectionDrag connects the dragged output to the input port under the
+// pointer, if any.
+func (c *Canvas) endConnectionDrag(port *PortWidget) {
+	source := c.dragSource
+	if source == nil {
+		return
+	}
+	c.content.Remove(c.dragLine)
+	c.dragSource = nil
+	c.dragLine = nil
+	c.highlightPorts(nil)
 
-	if err != nil {
-		c.showError("Execution Error", err.Error())
+	target := c.inputPortAt(c.dragPosition)
+	if target == nil || !c.canConnect(source, target) {
 		return
 	}
 
-	c.showExecutionResults(results)
+	c.nextConnID++
+	conn := c.newConnection(source, target)
+	conn.ID = fmt.Sprintf("conn_%d", c.nextConnID)
+	if err := c.history.AddConnection(conn); err != nil {
+		c.showError("Connection Error", err.Error())
+	}
+}
+
+func (c *Canvas) newConnection(source, target *PortWidget) types.Connection {
+	return types.Connection{
+		SourceNode: source.NodeID(),
+		SourcePort: source.name,
+		TargetNode: target.NodeID(),
+		TargetPort: target.name,
+	}
+}
+
+// canConnect reports whether the graph would accept a connection between
+// the ports, taking port types and existing connections into account.
+func (c *Canvas) canConnect(source, target *PortWidget) bool {
+	if target.NodeID() == source.NodeID() {
+		return false
+	}
+	return c.graph.ValidateConnection(c.newConnection(source, target)) == nil
+}
+
+// highlightPorts marks every input port as compatible or incompatible with
+// source. A nil source clears the highlighting.
+func (c *Canvas) highlightPorts(source *PortWidget) {
+	for _, widget := range c.nodeWidgets {
+		for _, port := range widget.inputPorts {
+			switch {
+			case source == nil:
+				port.SetHighlight(portNormal)
+			case c.canConnect(source, port):
+				port.SetHighlight(portCompatible)
+			default:
+				port.SetHighlight(portIncompatible)
+			}
+		}
+	}
+}
+
+func (c *Canvas) inputPortAt(pos fyne.Position) *PortWidget {
+	for _, widget := range c.nodeWidgets {
+		for _, port := range widget.inputPorts {
+			topLeft := c.absolutePosition(port)
+			size := port.Size()
+			if pos.X >= topLeft.X && pos.X <= topLeft.X+size.Width &&
+				pos.Y >= topLeft.Y && pos.Y <= topLeft.Y+size.Height {
+				return port
+			}
+		}
+	}
+	return nil
+}
+
+// updateConnections redraws the connections of a node after it moved.
+func (c *Canvas) updateConnections(nodeID string) {
+	for id, widget := range c.connections.GetConnections() {
+		conn := widget.Connection()
+		if conn.SourceNode != nodeID && conn.TargetNode != nodeID {
+			continue
+		}
+		source := c.nodeWidgets[conn.SourceNode].OutputPort(conn.SourcePort)
+		target := c.nodeWidgets[conn.TargetNode].InputPort(conn.TargetPort)
+		if source != nil && target != nil {
+			c.connections.UpdateConnection(id, c.portCenter(source), c.portCenter(target))
+		}
+	}
+}
+
+// portCenter returns the center of a port relative to the canvas content.
+func (c *Canvas) portCenter(port *PortWidget) fyne.Position {
+	pos := c.absolutePosition(port).Subtract(c.absolutePosition(c.content))
+	size := port.Size()
+	return pos.Add(fyne.NewPos(size.Width/2, size.Height/2))
+}
+
+func (c *Canvas) absolutePosition(object fyne.CanvasObject) fyne.Position {
+	return fyne.CurrentApp().Driver().AbsolutePositionForObject(object)
+}
+
+func (c *Canvas) executeGraph() {
+	ctx, runID := c.startRun()
+	go func() {
+		results, err := c.executor.ExecuteGraph(ctx)
+
+		fyne.Do(func() {
+			c.finishRun(runID)
+
+			// A stopped run still shows what it got done
+			if err != nil && ctx.Err() == nil {
+				c.showError("Execution Error", err.Error())
+				return
+			}
+
+			c.showExecutionResults(results)
+		})
+	}()
+}
+
+// startRun returns the context for a new run, which the Stop button
+// cancels, and an ID to pass to finishRun.
+func (c *Canvas) startRun() (context.Context, int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.nextRunID++
+	c.runs[c.nextRunID] = cancel
+	return ctx, c.nextRunID
+}
+
+// finishRun releases the context of a finished run.
+func (c *Canvas) finishRun(runID int) {
+	if cancel, exists := c.runs[runID]; exists {
+		cancel()
+		delete(c.runs, runID)
+	}
+}
+
+// stopRun cancels every run in progress. Nodes that are executing are
+// reported as cancelled and the nodes that have not started yet as skipped.
+func (c *Canvas) stopRun() {
+	for runID, cancel := range c.runs {
+		cancel()
+		delete(c.runs, runID)
+	}
+}
+
+// handleExecutionEvent highlights nodes while the executor runs them.
+func (c *Canvas) handleExecutionEvent(event types.ExecutionEvent) {
+	fyne.Do(func() {
+		widget, exists := c.nodeWidgets[event.NodeID]
+		if !exists {
+			return
+		}
+
+		if event.Type == types.EventNodeStarted {
+			widget.SetStatus(types.StatusRunning)
+		} else if event.Result != nil {
+			widget.UpdateResult(*event.Result)
+		}
+	})
 }
 
 func (c *Canvas) saveProject() {
@@ -152,6 +529,115 @@ func (c *Canvas) loadProject() {
 	fmt.Println("Load project functionality to be implemented")
 }
 
+// openTrace loads a recorded trace and replays it on the canvas against the
+// recorded HTTP responses.
+func (c *Canvas) openTrace() {
+	window := fyne.CurrentApp().Driver().AllWindows()[0]
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			c.showError("Open Trace", err.Error())
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		recorded, err := trace.Read(reader)
+		if err != nil {
+			c.showError("Open Trace", err.Error())
+			return
+		}
+		if err := c.showProject(recorded.Project); err != nil {
+			c.showError("Open Trace", err.Error())
+			return
+		}
+		c.replayTrace(recorded)
+	}, window)
+}
+
+// showProject replaces the nodes and connections on the canvas with those
+// of project. The edit history is cleared.
+func (c *Canvas) showProject(project *types.Project) error {
+	if err := c.factory.RegisterComposites(project.Composites); err != nil {
+		return err
+	}
+
+	for nodeID := range c.graph.GetAllNodes() {
+		c.graph.RemoveNode(nodeID)
+	}
+	for _, nodeData := range project.Nodes {
+		node, err := c.factory.CreateNodeFromData(nodeData)
+		if err != nil {
+			return fmt.Errorf("failed to create node %s: %w", nodeData.ID, err)
+		}
+		c.graph.AddNode(node)
+	}
+	for _, conn := range project.Connections {
+		if err := c.graph.AddConnection(conn); err != nil {
+			return fmt.Errorf("failed to add connection %s: %w", conn.ID, err)
+		}
+	}
+
+	c.history.Clear()
+	c.clearSelection()
+	c.executor.ClearResults()
+	return nil
+}
+
+// replayTrace replays the first failed run of a trace, or its first run if
+// none failed. The replay uses its own executor with the recorded variables
+// and options, so the canvas executor's variables and result cache are not
+// touched by recorded responses. Masked secrets are taken from the
+// environment as described at trace.ReplayVariables.
+func (c *Canvas) replayTrace(recorded *types.Trace) {
+	if len(recorded.Runs) == 0 {
+		c.showError("Open Trace", "trace contains no runs")
+		return
+	}
+	run := recorded.Runs[0]
+	for _, candidate := range recorded.Runs {
+		if candidate.Error != "" {
+			run = candidate
+			break
+		}
+	}
+
+	executor := core.NewExecutor(c.graph)
+	if recorded.Options.Concurrency > 0 {
+		executor.SetMaxConcurrency(recorded.Options.Concurrency)
+	}
+	executor.SetKeepGoing(recorded.Options.KeepGoing)
+	variables := trace.ReplayVariables(run)
+	executor.SetVariables(variables)
+	executor.SetHTTPSettings(recorded.Project.HTTP)
+	unsubscribe := executor.Observe(c.handleExecutionEvent)
+
+	runCtx, runID := c.startRun()
+	go func() {
+		defer unsubscribe()
+
+		ctx := core.WithTransport(runCtx, trace.NewReplayer(run.Exchanges, variables))
+		if recorded.Options.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, recorded.Options.Timeout)
+			defer cancel()
+		}
+
+		var results []types.ExecutionResult
+		if recorded.Target != "" {
+			results, _ = executor.ExecuteUpTo(ctx, recorded.Target)
+		} else {
+			results, _ = executor.ExecuteGraph(ctx)
+		}
+
+		fyne.Do(func() {
+			c.finishRun(runID)
+			c.showExecutionResults(results)
+		})
+	}()
+}
+
 func (c *Canvas) showError(title, message string) {
 	dialog := widget.NewModalPopUp(
 		container.NewVBox(
@@ -174,11 +660,19 @@ func (c *Canvas) showExecutionResults(results []types.ExecutionResult) {
 	content := ""
 	for _, result := range results {
 		status := "✓"
-		if !result.Success {
+		if result.Status == types.StatusSkipped {
+			status = "-"
+		} else if result.Status == types.StatusTimedOut {
+			status = "⏱"
+		} else if result.Status == types.StatusCancelled {
+			status = "⊘"
+		} else if !result.Success {
 			status = "✗"
 		}
 		content += fmt.Sprintf("%s %s (%v)\n", status, result.NodeID, result.Duration)
-		if !result.Success {
+		if result.Status == types.StatusSkipped {
+			content += fmt.Sprintf("  Skipped: %s\n", result.SkipReason)
+		} else if !result.Success {
 			content += fmt.Sprintf("  Error: %s\n", result.Error)
 		}
 	}
@@ -214,43 +708,46 @@ func (c *Canvas) getNextPosition() fyne.Position {
 }
 
 func (c *Canvas) executeNode(nodeID string) {
-	executor := core.NewExecutor(c.graph)
-
-	// Get dependencies and execute them first
-	deps := c.graph.GetDependencies(nodeID)
-	for _, depID := range deps {
-		if _, err := executor.ExecuteNode(context.Background(), depID); err != nil {
-			c.showError("Dependency Error", fmt.Sprintf("Failed to execute dependency %s: %v", depID, err))
-			return
-		}
-	}
-
-	// Execute the target node
-	result, err := executor.ExecuteNode(context.Background(), nodeID)
-	if err != nil {
-		c.showError("Execution Error", err.Error())
-		return
-	}
-
-	// Update node widget with result
-	if widget, exists := c.nodeWidgets[nodeID]; exists {
-		widget.UpdateResult(result)
-	}
-
-	// Show result
-	c.showNodeResult(result)
+	ctx, runID := c.startRun()
+	go func() {
+		// Run the node together with all of its upstream nodes
+		results, err := c.executor.ExecuteUpTo(ctx, nodeID)
+
+		fyne.Do(func() {
+			c.finishRun(runID)
+
+			for _, result := range results {
+				if result.NodeID == nodeID {
+					c.showNodeResult(result)
+					return
+				}
+			}
+
+			if err != nil {
+				c.showError("Execution Error", err.Error())
+			}
+		})
+	}()
 }
 
 func (c *Canvas) showNodeResult(result types.ExecutionResult) {
 	status := "Success"
-	if !result.Success {
+	if result.Status == types.StatusSkipped {
+		status = "Skipped"
+	} else if result.Status == types.StatusTimedOut {
+		status = "Timed out"
+	} else if result.Status == types.StatusCancelled {
+		status = "Cancelled"
+	} else if !result.Success {
 		status = "Failed"
 	}
 
 	content := fmt.Sprintf("Node: %s\nStatus: %s\nDuration: %v",
 		result.NodeID, status, result.Duration)
 
-	if !result.Success {
+	if result.Status == types.StatusSkipped {
+		content += fmt.Sprintf("\nReason: %s", result.SkipReason)
+	} else if !result.Success {
 		content += fmt.Sprintf("\nError: %s", result.Error)
 	} else {
 		content += "\nOutputs:\n"
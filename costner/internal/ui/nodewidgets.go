@@ -17,24 +17,89 @@ import (
 type NodeWidget struct {
 	node         types.Node
 	container    *DraggableWidget
+	background   *canvas.Rectangle
 	position     fyne.Position
 	onRun        func(nodeID string)
 	onMove       func(nodeID string, pos fyne.Position)
+	onInput      func(nodeID, name string, value interface{})
+	onDrop       func(nodeID string, pos fyne.Position)
+	onSelect     func(nodeID string)
 	lastResult   *types.ExecutionResult
+
+	inputPorts    map[string]*PortWidget
+	inputEditors  map[string]func(value interface{})
+	syncing       bool
+	outputPorts   map[string]*PortWidget
+	outputLabels  map[string]*widget.Label
+	onPortDrag    func(port *PortWidget, event *fyne.DragEvent)
+	onPortDragEnd func(port *PortWidget)
 }
 
 func NewNodeWidget(node types.Node, position fyne.Position) *NodeWidget {
 	w := &NodeWidget{
-		node:     node,
-		position: position,
+		node:        node,
+		position:    position,
+		inputPorts:   make(map[string]*PortWidget),
+		inputEditors: make(map[string]func(interface{})),
+		outputPorts:  make(map[string]*PortWidget),
+		outputLabels: make(map[string]*widget.Label),
 	}
 	w.createWidget()
 	return w
 }
 
-func (w *NodeWidget) SetCallbacks(onRun func(string), onMove func(string, fyne.Position)) {
+func (w *NodeWidget) SetCallbacks(onRun func(string), onMove func(string, fyne.Position), onInput func(string, string, interface{})) {
 	w.onRun = onRun
 	w.onMove = onMove
+	w.onInput = onInput
+}
+
+// SetDropCallback sets the handler called once the node has been dragged to
+// a new position.
+func (w *NodeWidget) SetDropCallback(onDrop func(string, fyne.Position)) {
+	w.onDrop = onDrop
+}
+
+// SetSelectCallback sets the handler called when the node is clicked with
+// Ctrl or Shift held, which adds it to or removes it from the selection.
+func (w *NodeWidget) SetSelectCallback(onSelect func(string)) {
+	w.onSelect = onSelect
+}
+
+// SetSelected highlights the node while it is part of the selection.
+func (w *NodeWidget) SetSelected(selected bool) {
+	if selected {
+		w.background.FillColor = theme.SelectionColor()
+	} else {
+		w.background.FillColor = theme.ButtonColor()
+	}
+	w.background.Refresh()
+}
+
+// SetPortCallbacks sets the handlers used to drag connections out of the
+// node's ports.
+func (w *NodeWidget) SetPortCallbacks(onDrag func(*PortWidget, *fyne.DragEvent), onDragEnd func(*PortWidget)) {
+	w.onPortDrag = onDrag
+	w.onPortDragEnd = onDragEnd
+}
+
+func (w *NodeWidget) InputPort(name string) *PortWidget {
+	return w.inputPorts[name]
+}
+
+func (w *NodeWidget) OutputPort(name string) *PortWidget {
+	return w.outputPorts[name]
+}
+
+func (w *NodeWidget) setInputValue(name string, value interface{}) {
+	if w.syncing {
+		return
+	}
+	if w.onInput != nil {
+		w.onInput(w.node.ID(), name, value)
+		return
+	}
+	w.node.SetInputValue(name, value)
 }
 
 func (w *NodeWidget) createWidget() {
@@ -42,6 +107,7 @@ func (w *NodeWidget) createWidget() {
 	bg := canvas.NewRectangle(theme.ButtonColor())
 	bg.StrokeWidth = 2
 	bg.StrokeColor = theme.PrimaryColor()
+	w.background = bg
 
 	// Create draggable header
 	header := widget.NewLabel(fmt.Sprintf("%s - %s", w.node.Type(), w.node.Name()))
@@ -88,6 +154,18 @@ func (w *NodeWidget) createWidget() {
 		}
 	})
 
+	w.container.onDrop = func(pos fyne.Position) {
+		if w.onDrop != nil {
+			w.onDrop(w.node.ID(), pos)
+		}
+	}
+
+	w.container.onClick = func(modifier fyne.KeyModifier) {
+		if modifier&(fyne.KeyModifierControl|fyne.KeyModifierShift) != 0 && w.onSelect != nil {
+			w.onSelect(w.node.ID())
+		}
+	}
+
 	w.container.Move(w.position)
 	w.container.Resize(fyne.NewSize(250, 320))
 }
@@ -125,11 +203,17 @@ func (w *NodeWidget) createInputWidget(input types.NodeInput) *fyne.Container {
 	switch input.Type {
 	case "bool":
 		check := widget.NewCheck("", func(checked bool) {
-			w.node.SetInputValue(input.Name, checked)
+			w.setInputValue(input.Name, checked)
 		})
 		if val, ok := input.Value.(bool); ok {
 			check.SetChecked(val)
 		}
+		w.inputEditors[input.Name] = func(value interface{}) {
+			val, _ := value.(bool)
+			if check.Checked != val {
+				check.SetChecked(val)
+			}
+		}
 		valueWidget = check
 
 	case "string":
@@ -138,7 +222,13 @@ func (w *NodeWidget) createInputWidget(input types.NodeInput) *fyne.Container {
 			entry.SetText(val)
 		}
 		entry.OnChanged = func(text string) {
-			w.node.SetInputValue(input.Name, text)
+			w.setInputValue(input.Name, text)
+		}
+		w.inputEditors[input.Name] = func(value interface{}) {
+			val, _ := value.(string)
+			if entry.Text != val {
+				entry.SetText(val)
+			}
 		}
 		valueWidget = entry
 
@@ -150,6 +240,11 @@ func (w *NodeWidget) createInputWidget(input types.NodeInput) *fyne.Container {
 		entry.OnChanged = func(text string) {
 			// TODO: Parse int and set value
 		}
+		w.inputEditors[input.Name] = func(value interface{}) {
+			if val, ok := value.(int); ok && entry.Text != fmt.Sprintf("%d", val) {
+				entry.SetText(fmt.Sprintf("%d", val))
+			}
+		}
 		valueWidget = entry
 
 	default:
@@ -158,16 +253,23 @@ func (w *NodeWidget) createInputWidget(input types.NodeInput) *fyne.Container {
 			entry.SetText(fmt.Sprintf("%v", input.Value))
 		}
 		entry.OnChanged = func(text string) {
-			w.node.SetInputValue(input.Name, text)
+			w.setInputValue(input.Name, text)
+		}
+		w.inputEditors[input.Name] = func(value interface{}) {
+			text := ""
+			if value != nil {
+				text = fmt.Sprintf("%v", value)
+			}
+			if entry.Text != text {
+				entry.SetText(text)
+			}
 		}
 		valueWidget = entry
 	}
 
 	// Create connection point
-	connectionPoint := widget.NewButton("◯", func() {
-		// TODO: Handle connection creation
-	})
-	connectionPoint.Resize(fyne.NewSize(20, 20))
+	connectionPoint := newPortWidget(w, input.Name, input.Type, true)
+	w.inputPorts[input.Name] = connectionPoint
 
 	return container.NewHBox(
 		connectionPoint,
@@ -184,12 +286,11 @@ func (w *NodeWidget) createOutputWidget(output types.NodeOutput) *fyne.Container
 	if output.Value != nil {
 		valueLabel.SetText(fmt.Sprintf("%v", output.Value))
 	}
+	w.outputLabels[output.Name] = valueLabel
 
 	// Create connection point
-	connectionPoint := widget.NewButton("◯", func() {
-		// TODO: Handle connection creation
-	})
-	connectionPoint.Resize(fyne.NewSize(20, 20))
+	connectionPoint := newPortWidget(w, output.Name, output.Type, false)
+	w.outputPorts[output.Name] = connectionPoint
 
 	return container.NewHBox(
 		label,
@@ -220,8 +321,48 @@ func parseFloat(s string, defaultVal float32) float32 {
 
 func (w *NodeWidget) UpdateResult(result types.ExecutionResult) {
 	w.lastResult = &result
-	// Update visual status based on result
-	// TODO: Change border color based on success/failure
+	w.SetStatus(result.Status)
+
+	// Outputs belong to the run, not the node, so show the latest ones here
+	for name, label := range w.outputLabels {
+		text := ""
+		if value, exists := result.Outputs[name]; exists && value != nil {
+			text = fmt.Sprintf("%v", value)
+		}
+		label.SetText(text)
+	}
+}
+
+// SetStatus highlights the node border according to its execution state.
+func (w *NodeWidget) SetStatus(status types.ExecutionStatus) {
+	switch status {
+	case types.StatusRunning:
+		w.background.StrokeColor = theme.WarningColor()
+	case types.StatusSuccess:
+		w.background.StrokeColor = theme.SuccessColor()
+	case types.StatusSkipped, types.StatusCancelled:
+		w.background.StrokeColor = theme.DisabledColor()
+	default:
+		w.background.StrokeColor = theme.ErrorColor()
+	}
+	w.background.Refresh()
+}
+
+// Sync updates the widget after its node was changed in the graph.
+func (w *NodeWidget) Sync() {
+	pos := w.node.GetPosition()
+	if position := fyne.NewPos(pos.X, pos.Y); position != w.container.Position() {
+		w.position = position
+		w.container.Move(position)
+	}
+
+	w.syncing = true
+	defer func() { w.syncing = false }()
+	for _, input := range w.node.GetInputs() {
+		if editor, exists := w.inputEditors[input.Name]; exists {
+			editor(input.Value)
+		}
+	}
 }
 
 func (w *NodeWidget) SetPosition(pos fyne.Position) {
@@ -237,7 +378,10 @@ type DraggableWidget struct {
 	widget.BaseWidget
 	content    fyne.CanvasObject
 	onMove     func(fyne.Position)
+	onDrop     func(fyne.Position)
+	onClick    func(fyne.KeyModifier)
 	isDragging bool
+	moved      bool
 	dragStart  fyne.Position
 }
 
@@ -260,11 +404,18 @@ func (d *DraggableWidget) CreateRenderer() fyne.WidgetRenderer {
 func (d *DraggableWidget) MouseDown(event *desktop.MouseEvent) {
 	if event.Button == desktop.MouseButtonPrimary {
 		d.isDragging = true
+		d.moved = false
 		d.dragStart = event.Position
 	}
 }
 
 func (d *DraggableWidget) MouseUp(event *desktop.MouseEvent) {
+	if d.isDragging && d.moved && d.onDrop != nil {
+		d.onDrop(d.Position())
+	}
+	if d.isDragging && !d.moved && d.onClick != nil {
+		d.onClick(event.Modifier)
+	}
 	d.isDragging = false
 }
 
@@ -281,6 +432,7 @@ func (d *DraggableWidget) MouseMoved(event *desktop.MouseEvent) {
 		newPos := fyne.NewPos(currentPos.X+deltaX, currentPos.Y+deltaY)
 
 		d.Move(newPos)
+		d.moved = true
 		if d.onMove != nil {
 			d.onMove(newPos)
 		}
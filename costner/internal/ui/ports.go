@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"costner/pkg/types"
+)
+
+type portHighlight int
+
+const (
+	portNormal portHighlight = iota
+	portCompatible
+	portIncompatible
+)
+
+// PortWidget is the connection point of a node input or output. Dragging
+// from an output port to an input port creates a connection.
+type PortWidget struct {
+	widget.BaseWidget
+	owner    *NodeWidget
+	name     string
+	portType types.PortType
+	isInput  bool
+	circle   *canvas.Circle
+}
+
+func newPortWidget(owner *NodeWidget, name string, portType types.PortType, isInput bool) *PortWidget {
+	p := &PortWidget{
+		owner:    owner,
+		name:     name,
+		portType: portType,
+		isInput:  isInput,
+	}
+	p.circle = canvas.NewCircle(color.Transparent)
+	p.circle.StrokeWidth = 2
+	p.SetHighlight(portNormal)
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *PortWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewGridWrap(fyne.NewSize(16, 16), p.circle))
+}
+
+func (p *PortWidget) NodeID() string {
+	return p.owner.node.ID()
+}
+
+func (p *PortWidget) SetHighlight(highlight portHighlight) {
+	switch highlight {
+	case portCompatible:
+		p.circle.FillColor = theme.SuccessColor()
+		p.circle.StrokeColor = theme.SuccessColor()
+	case portIncompatible:
+		p.circle.FillColor = color.Transparent
+		p.circle.StrokeColor = theme.DisabledColor()
+	default:
+		p.circle.FillColor = color.Transparent
+		p.circle.StrokeColor = theme.PrimaryColor()
+	}
+	p.circle.Refresh()
+}
+
+func (p *PortWidget) Dragged(event *fyne.DragEvent) {
+	if p.owner.onPortDrag != nil {
+		p.owner.onPortDrag(p, event)
+	}
+}
+
+func (p *PortWidget) DragEnd() {
+	if p.owner.onPortDragEnd != nil {
+		p.owner.onPortDragEnd(p)
+	}
+}
+
+// Mouse events are consumed so that dragging a port does not move the node.
+func (p *PortWidget) MouseDown(event *desktop.MouseEvent) {}
+func (p *PortWidget) MouseUp(event *desktop.MouseEvent)   {}
@@ -6,6 +6,7 @@ var (
 	ErrInputNotFound     = errors.New("input not found")
 	ErrOutputNotFound    = errors.New("output not found")
 	ErrInvalidConnection = errors.New("invalid connection")
+	ErrIncompatiblePorts = errors.New("incompatible port types")
 	ErrCyclicGraph       = errors.New("cyclic dependency detected")
 	ErrNodeNotFound      = errors.New("node not found")
 	ErrInvalidNodeType   = errors.New("invalid node type")
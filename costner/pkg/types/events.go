@@ -0,0 +1,53 @@
+package types
+
+import "time"
+
+type EventType string
+
+const (
+	EventNodeStarted   EventType = "node_started"
+	EventNodeSucceeded EventType = "node_succeeded"
+	EventNodeFailed    EventType = "node_failed"
+	EventNodeSkipped   EventType = "node_skipped"
+	EventGraphFinished EventType = "graph_finished"
+)
+
+type ExecutionEvent struct {
+	Type      EventType              `json:"type"`
+	NodeID    string                 `json:"node_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Duration  time.Duration          `json:"duration,omitempty"`
+	Outputs   map[string]interface{} `json:"outputs,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Result    *ExecutionResult       `json:"result,omitempty"`
+	Results   []ExecutionResult      `json:"results,omitempty"`
+}
+
+// ExecutionObserver receives execution events. Events are delivered one at
+// a time in the order they occur, from the goroutine that executed the node.
+type ExecutionObserver func(event ExecutionEvent)
+
+type GraphChangeType string
+
+const (
+	ChangeNodeAdded         GraphChangeType = "node_added"
+	ChangeNodeRemoved       GraphChangeType = "node_removed"
+	ChangeNodeUpdated       GraphChangeType = "node_updated"
+	ChangeConnectionAdded   GraphChangeType = "connection_added"
+	ChangeConnectionRemoved GraphChangeType = "connection_removed"
+)
+
+// GraphChange describes one modification of a graph. Node is set for node
+// changes and Connection for connection changes. Input names the input
+// whose value changed, if the update was an input change.
+type GraphChange struct {
+	Type       GraphChangeType `json:"type"`
+	NodeID     string          `json:"node_id,omitempty"`
+	Node       Node            `json:"-"`
+	Input      string          `json:"input,omitempty"`
+	Connection *Connection     `json:"connection,omitempty"`
+}
+
+// GraphObserver receives graph changes, in the order they were made, after
+// the graph has been unlocked. Observers must not modify the graph.
+type GraphObserver func(change GraphChange)
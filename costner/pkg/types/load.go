@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+// LoadReport summarises a load test. Nodes are listed in execution order.
+type LoadReport struct {
+	Users            int             `json:"users"`
+	Elapsed          time.Duration   `json:"elapsed"`
+	Iterations       int             `json:"iterations"`
+	FailedIterations int             `json:"failed_iterations"`
+	Nodes            []NodeLoadStats `json:"nodes"`
+}
+
+// NodeLoadStats holds the latency distribution of one node over all
+// iterations of a load test. Skipped executions are not counted.
+type NodeLoadStats struct {
+	NodeID     string        `json:"node_id"`
+	Executions int           `json:"executions"`
+	Errors     int           `json:"errors"`
+	Throughput float64       `json:"throughput"`
+	ErrorRate  float64       `json:"error_rate"`
+	Min        time.Duration `json:"min"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	AvgWait    time.Duration `json:"avg_wait"`
+}
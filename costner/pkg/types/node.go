@@ -7,7 +7,7 @@ import (
 
 type NodeInput struct {
 	Name        string      `json:"name"`
-	Type        string      `json:"type"`
+	Type        PortType    `json:"type"`
 	Required    bool        `json:"required"`
 	Description string      `json:"description"`
 	Value       interface{} `json:"value,omitempty"`
@@ -15,7 +15,7 @@ type NodeInput struct {
 
 type NodeOutput struct {
 	Name        string      `json:"name"`
-	Type        string      `json:"type"`
+	Type        PortType    `json:"type"`
 	Description string      `json:"description"`
 	Value       interface{} `json:"value,omitempty"`
 }
@@ -30,11 +30,42 @@ type Node interface {
 	GetOutputs() []NodeOutput
 	SetInputValue(name string, value interface{}) error
 	GetOutputValue(name string) (interface{}, bool)
+	GetConfig() map[string]interface{}
+	SetConfigValue(key string, value interface{})
+	GetPosition() Position
+	SetPosition(position Position)
 	Serialize() ([]byte, error)
 	Deserialize(data []byte) error
 	Clone() Node
 }
 
+// BranchingNode is implemented by nodes that only activate some of their
+// outputs on each execution. A branch port that is missing from the outputs
+// returned by Execute was not taken, and nodes wired to it are skipped.
+type BranchingNode interface {
+	BranchPorts() []string
+}
+
+// Versioned is implemented by nodes whose behaviour depends on more than
+// their type, inputs and config, such as composite instances, whose
+// definition can be edited. Cached results of such a node are only reused
+// while its version is unchanged.
+type Versioned interface {
+	Version() int
+}
+
+// SideEffecter is implemented by nodes whose execution affects the outside
+// world, such as sending HTTP requests. Plans never execute such nodes.
+type SideEffecter interface {
+	HasSideEffects() bool
+}
+
+// HasSideEffects reports whether executing node may affect the outside world.
+func HasSideEffects(node Node) bool {
+	effecter, ok := node.(SideEffecter)
+	return ok && effecter.HasSideEffects()
+}
+
 type BaseNode struct {
 	NodeID    string                 `json:"id"`
 	NodeType  string                 `json:"type"`
@@ -84,6 +115,25 @@ func (b *BaseNode) SetInputValue(name string, value interface{}) error {
 	return ErrInputNotFound
 }
 
+func (b *BaseNode) GetConfig() map[string]interface{} {
+	return b.Config
+}
+
+func (b *BaseNode) SetConfigValue(key string, value interface{}) {
+	if b.Config == nil {
+		b.Config = make(map[string]interface{})
+	}
+	b.Config[key] = value
+}
+
+func (b *BaseNode) GetPosition() Position {
+	return b.Position
+}
+
+func (b *BaseNode) SetPosition(position Position) {
+	b.Position = position
+}
+
 func (b *BaseNode) GetOutputValue(name string) (interface{}, bool) {
 	for _, output := range b.Outputs {
 		if output.Name == name {
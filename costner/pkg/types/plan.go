@@ -0,0 +1,37 @@
+package types
+
+// ExecutionPlan describes what executing a graph would do, without running
+// any node.
+type ExecutionPlan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is one node of an execution plan, in execution order. Inputs
+// holds the static input values with variables substituted and secrets
+// masked; Wired lists the inputs that receive values from connections.
+type PlanStep struct {
+	NodeID             string                 `json:"node_id"`
+	NodeType           string                 `json:"node_type"`
+	Name               string                 `json:"name"`
+	SideEffects        bool                   `json:"side_effects"`
+	Inputs             map[string]interface{} `json:"inputs,omitempty"`
+	Wired              []WiredInput           `json:"wired,omitempty"`
+	Unresolved         []string               `json:"unresolved,omitempty"`
+	UndefinedVariables []string               `json:"undefined_variables,omitempty"`
+}
+
+type WiredInput struct {
+	Input      string `json:"input"`
+	SourceNode string `json:"source_node"`
+	SourcePort string `json:"source_port"`
+}
+
+// Unresolved reports the number of required inputs without a value, summed
+// over all steps.
+func (p *ExecutionPlan) Unresolved() int {
+	count := 0
+	for _, step := range p.Steps {
+		count += len(step.Unresolved)
+	}
+	return count
+}
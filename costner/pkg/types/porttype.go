@@ -0,0 +1,101 @@
+package types
+
+import "fmt"
+
+// PortType is the kind of value carried by a node input or output. Port
+// types form a hierarchy rooted at PortAny, and a value of one type can be
+// used wherever one of its ancestors is expected:
+//
+//	any
+//	└── json
+//	    ├── primitive
+//	    │   ├── string
+//	    │   ├── bool
+//	    │   ├── duration
+//	    │   └── float
+//	    │       └── int
+//	    ├── map
+//	    └── list
+type PortType string
+
+const (
+	PortAny       PortType = "any"
+	PortJSON      PortType = "json"
+	PortPrimitive PortType = "primitive"
+	PortString    PortType = "string"
+	PortBool      PortType = "bool"
+	PortDuration  PortType = "duration"
+	PortFloat     PortType = "float"
+	PortInt       PortType = "int"
+	PortMap       PortType = "map"
+	PortList      PortType = "list"
+)
+
+var portTypeParents = map[PortType]PortType{
+	PortJSON:      PortAny,
+	PortPrimitive: PortJSON,
+	PortString:    PortPrimitive,
+	PortBool:      PortPrimitive,
+	PortDuration:  PortPrimitive,
+	PortFloat:     PortPrimitive,
+	PortInt:       PortFloat,
+	PortMap:       PortJSON,
+	PortList:      PortJSON,
+}
+
+// ParsePortType validates a port type name. An empty name means PortAny.
+func ParsePortType(name string) (PortType, error) {
+	t := PortType(name)
+	if t == "" {
+		return PortAny, nil
+	}
+	if t.Valid() {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown port type: %s", name)
+}
+
+func (t PortType) Valid() bool {
+	if t == PortAny {
+		return true
+	}
+	_, exists := portTypeParents[t]
+	return exists
+}
+
+// IsSubtypeOf reports whether t is other or one of its descendants.
+func (t PortType) IsSubtypeOf(other PortType) bool {
+	if t == "" {
+		t = PortAny
+	}
+	if other == "" {
+		other = PortAny
+	}
+	for current := t; ; {
+		if current == other {
+			return true
+		}
+		parent, exists := portTypeParents[current]
+		if !exists {
+			return false
+		}
+		current = parent
+	}
+}
+
+// AcceptsMultiple reports whether an input of this type may have more than
+// one incoming connection. List inputs collect the connected values and map
+// inputs merge them; every other input takes a single connection.
+func (t PortType) AcceptsMultiple() bool {
+	return t == PortList || t == PortMap
+}
+
+// CanConnect reports whether an output of type source may be wired to an
+// input of type target. Outputs typed any are only known at run time, so
+// they may feed every input.
+func CanConnect(source, target PortType) bool {
+	if source == "" || source == PortAny {
+		return true
+	}
+	return source.IsSubtypeOf(target)
+}
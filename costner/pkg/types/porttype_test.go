@@ -0,0 +1,64 @@
+package types
+
+import "testing"
+
+func TestCanConnect(t *testing.T) {
+	tests := []struct {
+		source, target PortType
+		want           bool
+	}{
+		// Outputs typed any are only known at run time
+		{PortAny, PortInt, true},
+		{"", PortMap, true},
+		{PortInt, PortAny, true},
+		{PortInt, "", true},
+
+		{PortInt, PortInt, true},
+		{PortInt, PortFloat, true},
+		{PortInt, PortPrimitive, true},
+		{PortInt, PortJSON, true},
+		{PortString, PortPrimitive, true},
+		{PortDuration, PortPrimitive, true},
+		{PortMap, PortJSON, true},
+		{PortList, PortJSON, true},
+		{PortPrimitive, PortJSON, true},
+
+		{PortFloat, PortInt, false},
+		{PortInt, PortString, false},
+		{PortString, PortBool, false},
+		{PortBool, PortDuration, false},
+		{PortMap, PortList, false},
+		{PortList, PortMap, false},
+		{PortMap, PortPrimitive, false},
+		{PortJSON, PortMap, false},
+		{PortJSON, PortString, false},
+		{PortPrimitive, PortString, false},
+	}
+
+	for _, test := range tests {
+		if got := CanConnect(test.source, test.target); got != test.want {
+			t.Errorf("CanConnect(%q, %q) = %v, want %v", test.source, test.target, got, test.want)
+		}
+	}
+}
+
+func TestParsePortType(t *testing.T) {
+	for name, want := range map[string]PortType{"": PortAny, "any": PortAny, "int": PortInt, "list": PortList} {
+		got, err := ParsePortType(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePortType(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParsePortType("number"); err == nil {
+		t.Error("ParsePortType accepted an unknown type")
+	}
+}
+
+func TestAcceptsMultiple(t *testing.T) {
+	for _, portType := range []PortType{PortAny, PortJSON, PortString, PortInt, PortList, PortMap} {
+		want := portType == PortList || portType == PortMap
+		if got := portType.AcceptsMultiple(); got != want {
+			t.Errorf("%s.AcceptsMultiple() = %v, want %v", portType, got, want)
+		}
+	}
+}
@@ -11,6 +11,35 @@ type Project struct {
 	Nodes       []NodeData             `json:"nodes"`
 	Connections []Connection           `json:"connections"`
 	Variables   map[string]interface{} `json:"variables"`
+	Dataset     *DatasetSource         `json:"dataset,omitempty"`
+	Composites  []CompositeDefinition  `json:"composites,omitempty"`
+	HTTP        *HTTPSettings          `json:"http,omitempty"`
+}
+
+// HTTPSettings configures the HTTP transport shared by the request nodes of
+// a run. Hosts maps a host, as it appears in request URLs, to its limits;
+// the "*" entry applies to every other host.
+type HTTPSettings struct {
+	Hosts map[string]HostLimits `json:"hosts,omitempty"`
+}
+
+// HostLimits bounds the requests sent to one host. RateLimit is in requests
+// per second and Burst is the number of requests that may be sent at once
+// after a quiet period. Zero values mean no limit.
+type HostLimits struct {
+	MaxConcurrency int     `json:"max_concurrency,omitempty"`
+	RateLimit      float64 `json:"rate_limit,omitempty"`
+	Burst          int     `json:"burst,omitempty"`
+}
+
+// DatasetSource points to a table of inputs for data-driven runs. Each row
+// is exposed to nodes as variables and the graph is executed once per row.
+// Format is csv, json (an array of objects) or ndjson; when empty it is
+// derived from the file extension. Relative paths are resolved against the
+// project file.
+type DatasetSource struct {
+	Path   string `json:"path"`
+	Format string `json:"format,omitempty"`
 }
 
 type NodeData struct {
@@ -23,11 +52,64 @@ type NodeData struct {
 	Outputs  []NodeOutput           `json:"outputs"`
 }
 
+// Subgraph is a nested graph owned by a container node such as ForEach.
+// It is stored in the container's config using the project file layout.
+type Subgraph struct {
+	Nodes       []NodeData   `json:"nodes"`
+	Connections []Connection `json:"connections"`
+}
+
+// CompositeDefinition is a reusable node type built from a subgraph. Its
+// ports map to unconnected ports of the inner nodes. Instances refer to the
+// definition by name, so editing it changes every instance.
+type CompositeDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Subgraph
+	Inputs  []CompositePort `json:"inputs"`
+	Outputs []CompositePort `json:"outputs"`
+}
+
+// CompositePort exposes the port Port of inner node Node as Name.
+type CompositePort struct {
+	Name string `json:"name"`
+	Node string `json:"node"`
+	Port string `json:"port"`
+}
+
+type ExecutionStatus string
+
+const (
+	StatusSuccess   ExecutionStatus = "success"
+	StatusFailed    ExecutionStatus = "failed"
+	StatusSkipped   ExecutionStatus = "skipped"
+	StatusTimedOut  ExecutionStatus = "timed_out"
+	StatusCancelled ExecutionStatus = "cancelled"
+	StatusRunning   ExecutionStatus = "running"
+)
+
 type ExecutionResult struct {
-	NodeID    string                 `json:"node_id"`
-	Success   bool                   `json:"success"`
-	Error     string                 `json:"error,omitempty"`
-	Outputs   map[string]interface{} `json:"outputs"`
-	Duration  time.Duration          `json:"duration"`
-	Timestamp time.Time              `json:"timestamp"`
+	NodeID     string                 `json:"node_id"`
+	Status     ExecutionStatus        `json:"status"`
+	Success    bool                   `json:"success"`
+	Error      string                 `json:"error,omitempty"`
+	SkipReason string                 `json:"skip_reason,omitempty"`
+	Cached     bool                   `json:"cached,omitempty"`
+	Inputs     map[string]interface{} `json:"inputs,omitempty"`
+	Outputs    map[string]interface{} `json:"outputs"`
+	Attempts   []AttemptResult        `json:"attempts,omitempty"`
+	Duration   time.Duration          `json:"duration"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// AttemptResult records a single try of a node executed under a retry
+// policy. Delay is the backoff waited before the next attempt.
+type AttemptResult struct {
+	Attempt    int           `json:"attempt"`
+	Success    bool          `json:"success"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Delay      time.Duration `json:"delay,omitempty"`
+	Timestamp  time.Time     `json:"timestamp"`
 }
\ No newline at end of file
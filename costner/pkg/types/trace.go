@@ -0,0 +1,58 @@
+package types
+
+import (
+	"net/http"
+	"time"
+)
+
+// TraceVersion is the format version written to trace files.
+const TraceVersion = 1
+
+// Trace is a recording of one or more runs of a project: the project
+// itself, every node's resolved inputs and outputs, and the HTTP exchanges
+// made while it ran. Values that look like secrets are masked.
+type Trace struct {
+	Version int          `json:"version"`
+	Project *Project     `json:"project"`
+	Target  string       `json:"target,omitempty"`
+	Options TraceOptions `json:"options"`
+	Runs    []TraceRun   `json:"runs"`
+}
+
+// TraceOptions are the execution options the recorded runs were started
+// with. A replay applies them again, so that its results can be compared
+// with the recording.
+type TraceOptions struct {
+	KeepGoing   bool          `json:"keep_going,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	Concurrency int           `json:"concurrency,omitempty"`
+}
+
+// TraceRun is one execution of the graph. Variables are the values the run
+// was started with, including dataset columns; those that look like secrets
+// are masked.
+type TraceRun struct {
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Start     time.Time              `json:"start"`
+	Duration  time.Duration          `json:"duration"`
+	Error     string                 `json:"error,omitempty"`
+	Nodes     []ExecutionResult      `json:"nodes"`
+	Exchanges []HTTPExchange         `json:"exchanges"`
+}
+
+// HTTPExchange is a recorded HTTP request and its response. NodeID is the
+// node that sent the request; for nodes in nested subgraphs it is the ID
+// within the subgraph.
+type HTTPExchange struct {
+	NodeID          string        `json:"node_id,omitempty"`
+	Method          string        `json:"method"`
+	URL             string        `json:"url"`
+	RequestHeaders  http.Header   `json:"request_headers,omitempty"`
+	RequestBody     string        `json:"request_body,omitempty"`
+	StatusCode      int           `json:"status_code,omitempty"`
+	ResponseHeaders http.Header   `json:"response_headers,omitempty"`
+	ResponseBody    string        `json:"response_body,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	Duration        time.Duration `json:"duration"`
+	Timestamp       time.Time     `json:"timestamp"`
+}